@@ -0,0 +1,470 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nsr888/lifecalendar/internal/app"
+	"github.com/nsr888/lifecalendar/internal/calendar"
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+	"github.com/nsr888/lifecalendar/internal/storage"
+)
+
+// command is a subcommand of the CLI.
+type command struct {
+	name    string
+	args    string // positional arguments shown in the usage line
+	summary string
+	help    string // optional details shown by "help <command>"
+	// setup defines the command's own flags on fs and returns the function
+	// running the command with the remaining arguments once they are parsed.
+	setup func(fs *flag.FlagSet) func(env *environment, args []string) error
+}
+
+// commands lists the subcommands in the order of the help text.
+var commands = []*command{
+	{name: "render", summary: "Render the calendars of the configured years", setup: setupRender},
+	{name: "plan", summary: "Print vacation plans as JSON", setup: setupPlan},
+	{name: "review", summary: "Review vacation plans with AI", setup: setupReview},
+	{
+		name:    "list",
+		summary: "List entries matching filters across years",
+		help:    listHelp,
+		setup:   setupList,
+	},
+	{
+		name:    "add",
+		args:    "<category> <dates> [label]",
+		summary: "Record an entry in a category",
+		help:    addHelp,
+		setup:   setupAdd,
+	},
+	{name: "validate", summary: "Check data files and print diagnostics", setup: setupValidate},
+	{name: "export", summary: "Export entries as iCalendar (.ics)", setup: setupExport},
+	{
+		name:    "import",
+		args:    "<file.ics>",
+		summary: "Import events from an iCalendar file",
+		setup:   setupImport,
+	},
+	{name: "init", summary: "Create a starter config and data folder", setup: setupInit},
+	{
+		name:    "migrate",
+		summary: "Copy the CSV data folder into the SQLite database",
+		setup:   setupMigrate,
+	},
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+func printUsage(globalFlags *flag.FlagSet) {
+	out := globalFlags.Output()
+	fmt.Fprintf(out, "Usage: %s [global flags] <command> [flags] [args]\n\nCommands:\n", programName)
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(out, "\nWithout a command the calendar is rendered. ")
+	fmt.Fprintf(out, "Run '%s help <command>' for its flags.\n\nGlobal flags:\n", programName)
+	globalFlags.PrintDefaults()
+	fmt.Fprintf(out, "\nExit codes: %d command failed, %d usage, %d config, %d data.\n",
+		exitCommand, exitUsage, exitConfig, exitData)
+}
+
+func printCommandUsage(cmd *command, fs *flag.FlagSet) {
+	out := fs.Output()
+	usage := strings.TrimSpace(fmt.Sprintf("%s %s [flags] %s", programName, cmd.name, cmd.args))
+	fmt.Fprintf(out, "Usage: %s\n\n%s.\n\n", usage, cmd.summary)
+	if cmd.help != "" {
+		fmt.Fprintf(out, "%s\n", cmd.help)
+	}
+	fmt.Fprintf(out, "Flags:\n")
+	fs.PrintDefaults()
+}
+
+// noArgs fails when a command that takes no arguments got some.
+func noArgs(args []string) error {
+	if len(args) > 0 {
+		return &usageError{err: fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))}
+	}
+
+	return nil
+}
+
+func setupRender(fs *flag.FlagSet) func(*environment, []string) error {
+	rolling := fs.Int("rolling", 0, "Render N months starting from the current month")
+	team := fs.Bool("team", false, "Render the team view of the configured people")
+
+	return func(env *environment, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+
+		cfg, err := env.config()
+		if err != nil {
+			return err
+		}
+		if *rolling != 0 {
+			if rollingErr := cfg.SetRolling(*rolling, entity.Today()); rollingErr != nil {
+				return &configError{err: fmt.Errorf("invalid -rolling: %w", rollingErr)}
+			}
+		}
+
+		appService, _, err := env.service()
+		if err != nil {
+			return err
+		}
+
+		if *team {
+			return appService.RunTeam(cfg, openPeopleStorage(cfg))
+		}
+
+		return appService.Run(cfg)
+	}
+}
+
+func setupPlan(*flag.FlagSet) func(*environment, []string) error {
+	return func(env *environment, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+
+		appService, cfg, err := env.service()
+		if err != nil {
+			return err
+		}
+
+		cfg.JSONPlan = true
+		return appService.RunJSONPlan(cfg)
+	}
+}
+
+func setupReview(*flag.FlagSet) func(*environment, []string) error {
+	return func(env *environment, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+
+		appService, cfg, err := env.service()
+		if err != nil {
+			return err
+		}
+
+		cfg.AIReview = true
+		return appService.RunAIReview(cfg)
+	}
+}
+
+const listHelp = `Every year with data is searched unless -year is given. The working days
+of an entry leave out weekends, days off of the work schedules and public
+holidays, like the allowance does.
+
+Examples:
+  lifecalendar list -category vacations -dates 2025-Q3
+  lifecalendar list -label conference -since 2023 -format csv
+`
+
+func setupList(fs *flag.FlagSet) func(*environment, []string) error {
+	categories := fs.String("category", "", "Comma-separated categories (default: all)")
+	label := fs.String("label", "", "Case-insensitive substring of the label")
+	match := fs.String("match", "", "Regular expression matched against the label")
+	dates := fs.String("dates", "", "Entries sharing a day with a date expression, e.g. 2025-Q3")
+	since := fs.String("since", "", "Entries ending on or after the start of a date expression")
+	until := fs.String("until", "", "Entries starting on or before the end of a date expression")
+	minDays := fs.Float64("min-days", 0, "Minimum total days of an entry")
+	format := fs.String("format", app.ListFormatTable, "Output format: table, csv or json")
+
+	return func(env *environment, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+
+		filter, err := listFilter(*dates, *since, *until)
+		if err != nil {
+			return &usageError{err: err}
+		}
+		filter.Categories = splitList(*categories)
+		filter.Label = *label
+		filter.MinDays = *minDays
+		filter.Years = env.globals.years
+
+		if *match != "" {
+			if filter.Match, err = regexp.Compile(*match); err != nil {
+				return &usageError{err: fmt.Errorf("invalid -match: %w", err)}
+			}
+		}
+
+		switch *format {
+		case app.ListFormatTable, app.ListFormatCSV, app.ListFormatJSON:
+		default:
+			return &usageError{err: fmt.Errorf("unknown format %q: use table, csv or json", *format)}
+		}
+
+		appService, cfg, err := env.service()
+		if err != nil {
+			return err
+		}
+
+		return appService.RunList(cfg, filter, *format)
+	}
+}
+
+// listFilter builds the date bounds of a list filter from the -dates,
+// -since and -until expressions.
+func listFilter(dates, since, until string) (app.ListFilter, error) {
+	var filter app.ListFilter
+	today := entity.Today()
+
+	if dates != "" {
+		start, end, err := calendar.ParseDateRange(dates, today)
+		if err != nil {
+			return filter, fmt.Errorf("invalid -dates: %w", err)
+		}
+		filter.From, filter.To = start, end
+	}
+	if since != "" {
+		start, _, err := calendar.ParseDateRange(since, today)
+		if err != nil {
+			return filter, fmt.Errorf("invalid -since: %w", err)
+		}
+		filter.From = start
+	}
+	if until != "" {
+		_, end, err := calendar.ParseDateRange(until, today)
+		if err != nil {
+			return filter, fmt.Errorf("invalid -until: %w", err)
+		}
+		filter.To = end
+	}
+
+	return filter, nil
+}
+
+const addHelp = `The entry is split at year boundaries, so every part lands in its own
+year folder. Entries overlapping existing ones of the category are refused
+unless -force is given, and public holidays inside the entry are warned
+about. The affected months are printed afterwards.
+
+Dates are a date or two joined by "..":
+  2025-07-01..2025-07-14   a range
+  2025-W32                 Monday to Sunday of an ISO week
+  today, tomorrow          also yesterday
+  next friday              the first Friday after today; "last friday" too
+  2025-Q3, 2025-07, 2025   a quarter, a month or a year
+  +3d, +2w                 from today, or from the start when ending a range
+
+Example:
+  lifecalendar add vacations 2025-07-01..+13d "Italy trip"
+`
+
+func setupAdd(fs *flag.FlagSet) func(*environment, []string) error {
+	portion := fs.String("portion", "", "Part of each day: am, pm or 0.5 (default: full days)")
+	force := fs.Bool("force", false, "Add the entry even if it overlaps existing ones")
+
+	return func(env *environment, args []string) error {
+		// "next friday" and "last friday" may be passed unquoted.
+		if len(args) > 2 && (args[1] == "next" || args[1] == "last") {
+			args = append([]string{args[0], args[1] + " " + args[2]}, args[3:]...)
+		}
+		if len(args) < 2 || len(args) > 3 {
+			return &usageError{err: errors.New("expected a category, dates and an optional label")}
+		}
+
+		start, end, err := calendar.ParseDateRange(args[1], entity.Today())
+		if err != nil {
+			return &usageError{err: err}
+		}
+
+		entry := entity.CategoryEntry{DateStart: start, DateEnd: end}
+		if len(args) == 3 {
+			entry.Label = args[2]
+		}
+		if entry.Portion, err = entity.ParseDayPortion(*portion); err != nil {
+			return &usageError{err: err}
+		}
+
+		appService, cfg, err := env.service()
+		if err != nil {
+			return err
+		}
+
+		return appService.RunAdd(cfg, args[0], entry, *force)
+	}
+}
+
+func setupValidate(*flag.FlagSet) func(*environment, []string) error {
+	return func(env *environment, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+
+		appService, cfg, err := env.service()
+		if err != nil {
+			return err
+		}
+
+		return appService.RunValidate(cfg)
+	}
+}
+
+func setupExport(fs *flag.FlagSet) func(*environment, []string) error {
+	categories := fs.String("categories", "", "Comma-separated categories to export (default: all)")
+
+	return func(env *environment, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+
+		appService, cfg, err := env.service()
+		if err != nil {
+			return err
+		}
+
+		return appService.RunExportICS(cfg, splitList(*categories))
+	}
+}
+
+func setupImport(fs *flag.FlagSet) func(*environment, []string) error {
+	category := fs.String("category", "", "Target category of the imported events")
+
+	return func(env *environment, args []string) error {
+		if len(args) != 1 {
+			return &usageError{err: errors.New("expected exactly one .ics file")}
+		}
+
+		appService, cfg, err := env.service()
+		if err != nil {
+			return err
+		}
+
+		return appService.RunImportICS(cfg, args[0], *category)
+	}
+}
+
+// starterConfig is the config written by init, formatted with the year and
+// the data folder.
+const starterConfig = `# Years to render calendars for
+years = [%d]
+
+# Data folder path, one folder per year with one CSV file per category
+data_folder = %q
+
+[rendering]
+first_weekday = 0  # Monday = 0, Sunday = 6
+weekend_days = [5, 6]  # Saturday = 5, Sunday = 6
+
+# Generate public holidays from built-in rules: "DE", "FR", "GB" or "US"
+# [holidays]
+# country = "DE"
+
+[categories.current_day]
+bg = "#cc0000"
+fg = "#ffffff"
+bold = true
+priority = 0
+
+[categories.weekends]
+fg = "#d8d8d8"
+priority = 1
+
+[categories.public_holidays]
+bg = "#7a2936"
+fg = "#ffffff"
+priority = 2
+
+[categories.vacations]
+bg = "#225c2b"
+fg = "#ffffff"
+priority = 3
+`
+
+func setupInit(fs *flag.FlagSet) func(*environment, []string) error {
+	force := fs.Bool("force", false, "Overwrite an existing config file")
+
+	return func(env *environment, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+
+		configPath := env.globals.configPath
+		if _, err := os.Stat(configPath); err == nil && !*force {
+			return fmt.Errorf("%s already exists, use -force to overwrite it", configPath)
+		}
+
+		dataFolder := env.globals.dataFolder
+		if dataFolder == "" {
+			dataFolder = "data"
+		}
+		year := entity.Today().Year()
+		if len(env.globals.years) > 0 {
+			year = env.globals.years[0]
+		}
+
+		content := fmt.Sprintf(starterConfig, year, dataFolder)
+		if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+
+		// The data folder is relative to the working directory, like the
+		// data_folder setting.
+		csvStorage := storage.NewCSVStorage(dataFolder)
+		err := csvStorage.CreateCategory(year, "vacations")
+		if err != nil && !errors.Is(err, storage.ErrCategoryExists) {
+			return fmt.Errorf("failed to create data folder: %w", err)
+		}
+
+		env.logger.Printf("Created %s and %s", configPath,
+			filepath.Join(dataFolder, strconv.Itoa(year), "vacations.csv"))
+		return nil
+	}
+}
+
+func setupMigrate(*flag.FlagSet) func(*environment, []string) error {
+	return func(env *environment, args []string) error {
+		if err := noArgs(args); err != nil {
+			return err
+		}
+
+		cfg, err := env.config()
+		if err != nil {
+			return err
+		}
+
+		return migrateCSVToSQLite(cfg, env)
+	}
+}
+
+func migrateCSVToSQLite(cfg *config.Config, env *environment) error {
+	csvStorage := storage.NewCSVStorage(cfg.GetDataFolderWithFallback())
+
+	sqliteStorage, err := storage.NewSQLiteStorage(cfg.GetSQLitePath())
+	if err != nil {
+		return err
+	}
+	defer sqliteStorage.Close()
+
+	migrated, err := storage.MigrateCSVToSQLite(csvStorage, sqliteStorage)
+	if err != nil {
+		return err
+	}
+
+	env.logger.Printf("Migrated %d entries to %s", migrated, cfg.GetSQLitePath())
+	return nil
+}
@@ -1,57 +1,291 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/nsr888/lifecalendar/internal/app"
 	"github.com/nsr888/lifecalendar/internal/config"
 	"github.com/nsr888/lifecalendar/internal/storage"
 )
 
+// Exit codes tell scripts which part of a run failed.
+const (
+	exitOK      = 0
+	exitCommand = 1 // the command itself failed
+	exitUsage   = 2 // unknown command or invalid flags
+	exitConfig  = 3 // the config could not be loaded or is invalid
+	exitData    = 4 // the data could not be read or is invalid
+)
+
+const (
+	programName       = "lifecalendar"
+	defaultConfigPath = "config.toml"
+)
+
 func main() {
 	logger := log.New(os.Stdout, "APP: ", log.LstdFlags)
+	os.Exit(run(os.Args[1:], logger))
+}
+
+// globalFlags are accepted before and after the command name and override
+// the matching config fields.
+type globalFlags struct {
+	configPath string
+	dataFolder string
+	years      yearList
+}
 
-	var jsonPlan bool
-	var aiReview bool
-	flag.BoolVar(&jsonPlan, "json-plan", false, "Output vacation plans as JSON with weekend/holiday data")
-	flag.BoolVar(&aiReview, "ai-review", false, "Review vacation plans with AI and print analysis")
-	flag.Parse()
+// register defines the global flags on fs. The current values become the
+// defaults, so flags given before the command name survive registering them
+// again on the command's flag set.
+func (g *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.configPath, "config", g.configPath, "Path to the config file")
+	fs.StringVar(&g.dataFolder, "data", g.dataFolder, "Data folder, overrides data_folder")
+	fs.Var(&g.years, "year", "Year to work on, overrides years; repeat or separate with commas")
+}
 
-	var appConfig *config.Config
-	var err error
+// load loads the config and applies the overrides.
+func (g *globalFlags) load() (*config.Config, error) {
+	cfg, err := config.Load(g.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config %s: %w", g.configPath, err)
+	}
 
-	// Get config file path from remaining arguments
-	args := flag.Args()
-	if len(args) > 0 {
-		appConfig, err = config.Load(args[0])
-	} else {
-		appConfig, err = config.LoadDefault()
+	if g.dataFolder != "" {
+		cfg.DataFolder = g.dataFolder
+	}
+	if len(g.years) > 0 {
+		cfg.Years = g.years
 	}
 
+	return cfg, nil
+}
+
+// yearList is a flag.Value collecting years from repeated or comma-separated values.
+type yearList []int
+
+func (y *yearList) String() string {
+	years := make([]string, len(*y))
+	for i, year := range *y {
+		years[i] = strconv.Itoa(year)
+	}
+
+	return strings.Join(years, ",")
+}
+
+func (y *yearList) Set(value string) error {
+	for _, item := range splitList(value) {
+		year, err := strconv.Atoi(item)
+		if err != nil {
+			return fmt.Errorf("invalid year %q", item)
+		}
+		*y = append(*y, year)
+	}
+
+	return nil
+}
+
+// run executes the command line and returns the exit code.
+func run(args []string, logger *log.Logger) int {
+	globals := &globalFlags{configPath: defaultConfigPath}
+
+	fs := flag.NewFlagSet(programName, flag.ContinueOnError)
+	globals.register(fs)
+	fs.Usage = func() { printUsage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return usageExitCode(err)
+	}
+
+	cmd, args, err := selectCommand(fs.Args(), globals)
 	if err != nil {
-		logger.Fatalf("Failed to load app config: %v", err)
+		fmt.Fprintf(fs.Output(), "%v\nRun '%s help' for usage.\n", err, programName)
+		return exitUsage
+	}
+	if cmd == nil {
+		printUsage(fs)
+		return exitOK
+	}
+
+	cmdFlags := flag.NewFlagSet(programName+" "+cmd.name, flag.ContinueOnError)
+	runCommand := cmd.setup(cmdFlags)
+	globals.register(cmdFlags)
+	cmdFlags.Usage = func() { printCommandUsage(cmd, cmdFlags) }
+
+	if err = cmdFlags.Parse(args); err != nil {
+		return usageExitCode(err)
+	}
+
+	env := &environment{globals: globals, logger: logger}
+	defer env.close()
+
+	if err = runCommand(env, cmdFlags.Args()); err != nil {
+		logger.Printf("%s failed: %v", cmd.name, err)
+		return exitCode(err)
 	}
 
-	// Store flags in config
-	appConfig.JSONPlan = jsonPlan
-	appConfig.AIReview = aiReview
+	return exitOK
+}
 
-	csvStorage := storage.NewCSVStorage(appConfig.GetDataFolderWithFallback())
-	appService := app.NewService(csvStorage, logger)
+// selectCommand picks the command named by the first argument. Without one
+// the calendar is rendered, and a lone .toml argument is taken as the config
+// path as before commands existed. A nil command means help was asked for.
+func selectCommand(args []string, globals *globalFlags) (*command, []string, error) {
+	if len(args) == 0 {
+		return findCommand("render"), nil, nil
+	}
 
-	if aiReview {
-		if runErr := appService.RunAIReview(appConfig); runErr != nil {
-			logger.Fatalf("Application failed: %v", runErr)
+	if args[0] == "help" {
+		if len(args) == 1 {
+			return nil, nil, nil
 		}
-	} else if jsonPlan {
-		if runErr := appService.RunJSONPlan(appConfig); runErr != nil {
-			logger.Fatalf("Application failed: %v", runErr)
+		if cmd := findCommand(args[1]); cmd != nil {
+			return cmd, []string{"-h"}, nil
 		}
-	} else {
-		if runErr := appService.Run(appConfig); runErr != nil {
-			logger.Fatalf("Application failed: %v", runErr)
+		return nil, nil, fmt.Errorf("unknown command %q", args[1])
+	}
+
+	if cmd := findCommand(args[0]); cmd != nil {
+		return cmd, args[1:], nil
+	}
+
+	if len(args) == 1 && strings.HasSuffix(args[0], ".toml") {
+		globals.configPath = args[0]
+		return findCommand("render"), nil, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown command %q", args[0])
+}
+
+func usageExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	return exitUsage
+}
+
+// exitCode maps a command failure to the exit code of its cause.
+func exitCode(err error) int {
+	var argsErr *usageError
+	var cfgErr *configError
+	var dataErr *storage.DataError
+
+	switch {
+	case errors.As(err, &argsErr):
+		return exitUsage
+	case errors.As(err, &cfgErr):
+		return exitConfig
+	case errors.As(err, &dataErr):
+		return exitData
+	default:
+		return exitCommand
+	}
+}
+
+// usageError marks invalid command arguments.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// configError marks a failure to load or apply the config.
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string { return e.err.Error() }
+func (e *configError) Unwrap() error { return e.err }
+
+// environment gives commands the config and the storage, opening them on
+// first use.
+type environment struct {
+	globals *globalFlags
+	logger  *log.Logger
+
+	cfg          *config.Config
+	closeStorage func() error
+}
+
+// config returns the config with the global overrides applied.
+func (e *environment) config() (*config.Config, error) {
+	if e.cfg != nil {
+		return e.cfg, nil
+	}
+
+	cfg, err := e.globals.load()
+	if err != nil {
+		return nil, &configError{err: err}
+	}
+	e.cfg = cfg
+
+	return cfg, nil
+}
+
+// service opens the configured storage and returns the app service on it
+// together with the config.
+func (e *environment) service() (*app.Service, *config.Config, error) {
+	cfg, err := e.config()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataStorage, closeStorage, err := openStorage(cfg)
+	if err != nil {
+		return nil, nil, &storage.DataError{Err: fmt.Errorf("failed to open storage: %w", err)}
+	}
+	e.closeStorage = closeStorage
+
+	return app.NewService(dataStorage, e.logger), cfg, nil
+}
+
+func (e *environment) close() {
+	if e.closeStorage != nil {
+		e.closeStorage()
+	}
+}
+
+// openStorage creates the storage backend selected in the config, caching
+// the years it loads.
+func openStorage(cfg *config.Config) (storage.Storage, func() error, error) {
+	if cfg.Storage == config.StorageSQLite {
+		sqliteStorage, err := storage.NewSQLiteStorage(cfg.GetSQLitePath())
+		if err != nil {
+			return nil, nil, err
 		}
+		return storage.NewCachedStorage(sqliteStorage), sqliteStorage.Close, nil
+	}
+
+	csvStorage := storage.NewCSVStorage(cfg.GetDataFolderWithFallback())
+	return storage.NewCachedStorage(csvStorage), func() error { return nil }, nil
+}
+
+// openPeopleStorage opens the CSV data folder of every configured person.
+func openPeopleStorage(cfg *config.Config) map[string]storage.Storage {
+	people := make(map[string]storage.Storage, len(cfg.People))
+	for name, person := range cfg.People {
+		people[name] = storage.NewCachedStorage(storage.NewCSVStorage(person.DataFolder))
 	}
+
+	return people
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
 }
@@ -0,0 +1,112 @@
+package allowance
+
+import (
+	"math"
+
+	"github.com/nsr888/lifecalendar/internal/calendar"
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+// Compute returns the vacation balance of the period. Leave days are the
+// vacation and personal working days counted by calendar.CountDaysInPeriod:
+// days up to and including today are used, later ones are planned.
+//
+// The yearly entitlement is prorated for periods that are not twelve months
+// long. With monthly accrual only the months started so far count towards
+// the entitlement of the current period; past and future periods get the
+// full entitlement. Carried-over days are spent first and the part not
+// booked by the expiry date is forfeited.
+func Compute(
+	rules config.Allowance,
+	period entity.Period,
+	data *entity.CategoryName,
+	ctx *entity.RenderContext,
+	carriedOver float64,
+	today entity.Date,
+) entity.VacationBalance {
+	periodEnd := period.End.AddDate(0, 0, 1)
+
+	split := entity.NewDate(today.Year(), today.Month(), today.Day()+1)
+	if split.Before(period.Start) {
+		split = period.Start
+	}
+	if split.After(periodEnd) {
+		split = periodEnd
+	}
+
+	balance := entity.VacationBalance{
+		Entitlement: entitlement(rules, period, today),
+		CarriedOver: carriedOver,
+		Used:        leaveDays(data, period.Start, split, ctx),
+		Planned:     leaveDays(data, split, periodEnd, ctx),
+	}
+
+	if expiry, ok := expiryDate(rules, period); ok {
+		booked := leaveDays(data, period.Start, expiry.AddDate(0, 0, 1), ctx)
+		balance.Expired = math.Max(0, carriedOver-booked)
+	}
+
+	balance.Remaining = balance.Entitlement + balance.CarriedOver - balance.Expired -
+		balance.Used - balance.Planned
+
+	return round(balance)
+}
+
+// CarryOver returns the days moved from a year with the given balance into
+// the next one: the unspent remainder, limited by the carry-over cap.
+func CarryOver(rules config.Allowance, previous entity.VacationBalance) float64 {
+	return math.Min(math.Max(previous.Remaining, 0), math.Max(rules.CarryOverCap, 0))
+}
+
+func entitlement(rules config.Allowance, period entity.Period, today entity.Date) float64 {
+	months := len(period.Months())
+	days := rules.EntitlementFor(period.Start.Year()) * float64(months) / 12
+
+	if !rules.MonthlyAccrual || !period.Contains(today) {
+		return days
+	}
+
+	elapsed := 0
+	for _, month := range period.Months() {
+		if !month.After(today) {
+			elapsed++
+		}
+	}
+
+	return days * float64(elapsed) / float64(months)
+}
+
+// expiryDate returns the first carry-over expiry date inside the period.
+func expiryDate(rules config.Allowance, period entity.Period) (entity.Date, bool) {
+	expiry, ok := rules.CarryOverExpiryDate(period.Start.Year())
+	if ok && expiry.Before(period.Start) {
+		expiry, ok = rules.CarryOverExpiryDate(period.Start.Year() + 1)
+	}
+
+	return expiry, ok
+}
+
+// leaveDays counts vacation and personal working days in [start, end).
+func leaveDays(
+	data *entity.CategoryName,
+	start, end entity.Date,
+	ctx *entity.RenderContext,
+) float64 {
+	vacationDays, personalDays := calendar.CountDaysInPeriod(data, start, end, ctx)
+	return vacationDays + personalDays
+}
+
+// round keeps two decimals, enough for accrued fractions of a day.
+func round(balance entity.VacationBalance) entity.VacationBalance {
+	r := func(v float64) float64 { return math.Round(v*100) / 100 }
+
+	return entity.VacationBalance{
+		Entitlement: r(balance.Entitlement),
+		CarriedOver: r(balance.CarriedOver),
+		Expired:     r(balance.Expired),
+		Used:        r(balance.Used),
+		Planned:     r(balance.Planned),
+		Remaining:   r(balance.Remaining),
+	}
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+// RunAdd records an entry in the category, split at year boundaries so every
+// part goes to its own year folder. Entries overlapping existing ones of the
+// category are refused unless force is set, and public holidays inside the
+// entry are warned about. The affected months are rendered afterwards.
+func (s *Service) RunAdd(
+	cfg *config.Config,
+	category string,
+	entry entity.CategoryEntry,
+	force bool,
+) error {
+	parts := entry.SplitByYear()
+
+	overlaps, err := s.overlappingEntries(category, parts)
+	if err != nil {
+		return err
+	}
+	for _, existing := range overlaps {
+		s.logger.Printf("Overlaps %s entry %s", category, describeEntry(existing))
+	}
+	if len(overlaps) > 0 && !force {
+		return fmt.Errorf(
+			"entry overlaps %d existing %s entries, use -force to add it anyway",
+			len(overlaps),
+			category,
+		)
+	}
+
+	if category != publicHolidaysCategory {
+		if err = s.warnAboutHolidays(cfg, category, parts); err != nil {
+			return err
+		}
+	}
+
+	for _, part := range parts {
+		year := part.DateStart.Year()
+		if err = s.storage.AddEntry(year, category, part); err != nil {
+			return fmt.Errorf("failed to add entry to %s/%d: %w", category, year, err)
+		}
+		s.logger.Printf("Added %s to %s/%d", describeEntry(part), category, year)
+	}
+
+	monthConfig := *cfg
+	monthConfig.SetCustomPeriod(
+		entity.NewDate(entry.DateStart.Year(), entry.DateStart.Month(), 1),
+		entity.NewDate(entry.DateEnd.Year(), entry.DateEnd.Month()+1, 0),
+	)
+	if monthConfig.Rendering.Format == config.FormatLife {
+		monthConfig.Rendering.Format = "compact"
+	}
+
+	return s.Run(&monthConfig)
+}
+
+// overlappingEntries returns the entries of the category booking a day twice
+// with one of the parts. Entries spanning years are returned once.
+func (s *Service) overlappingEntries(
+	category string,
+	parts []entity.CategoryEntry,
+) ([]entity.CategoryEntry, error) {
+	var overlaps []entity.CategoryEntry
+	seen := make(map[entity.CategoryEntry]struct{})
+
+	for _, part := range parts {
+		year := part.DateStart.Year()
+		if !s.storage.IsYearDataExists(year) {
+			continue
+		}
+
+		dataConfig, err := s.storage.LoadCategoryByYear(year)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load data config for year %d: %w", year, err)
+		}
+
+		existingCategory, exists := dataConfig.Categories[category]
+		if !exists {
+			continue
+		}
+
+		for _, existing := range existingCategory.Entries {
+			if _, duplicate := seen[existing]; duplicate || !existing.Conflicts(part) {
+				continue
+			}
+			seen[existing] = struct{}{}
+			overlaps = append(overlaps, existing)
+		}
+	}
+
+	return overlaps, nil
+}
+
+// warnAboutHolidays logs every stored or generated public holiday inside the parts.
+func (s *Service) warnAboutHolidays(
+	cfg *config.Config,
+	category string,
+	parts []entity.CategoryEntry,
+) error {
+	seen := make(map[entity.CategoryEntry]struct{})
+
+	for _, part := range parts {
+		dataConfig, err := s.loadCategoryByYearWithHolidays(part.DateStart.Year(), cfg)
+		if err != nil {
+			return err
+		}
+
+		holidays, exists := dataConfig.Categories[publicHolidaysCategory]
+		if !exists {
+			continue
+		}
+
+		for _, holiday := range holidays.Entries {
+			if _, duplicate := seen[holiday]; duplicate {
+				continue
+			}
+			if holiday.Overlaps(part.DateStart, part.DateEnd) {
+				seen[holiday] = struct{}{}
+				s.logger.Printf(
+					"Warning: %s entry includes the public holiday %s",
+					category,
+					describeEntry(holiday),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// describeEntry formats an entry for log lines, e.g. "2025-07-01..2025-07-14 Italy trip".
+func describeEntry(entry entity.CategoryEntry) string {
+	dates := entry.DateStart.String()
+	if entry.DateEnd != entry.DateStart {
+		dates += ".." + entry.DateEnd.String()
+	}
+	if entry.Portion != entity.PortionFull {
+		dates += " (" + string(entry.Portion) + ")"
+	}
+	if label := entryLabel(entry); label != "" {
+		return dates + " " + label
+	}
+
+	return dates
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"github.com/nsr888/lifecalendar/internal/allowance"
+	"github.com/nsr888/lifecalendar/internal/calendar"
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+// vacationBalance computes the allowance balance of the period, or nil when
+// no entitlement is configured. For twelve-month periods the carry-over is
+// taken from the balance of the previous period as long as there is data
+// for the year it starts in.
+func (s *Service) vacationBalance(
+	period entity.Period,
+	cfg *config.Config,
+	ctx *entity.RenderContext,
+	cache map[entity.Date]entity.VacationBalance,
+) (*entity.VacationBalance, error) {
+	if !cfg.Allowance.Enabled() {
+		return nil, nil
+	}
+
+	if balance, exists := cache[period.Start]; exists {
+		return &balance, nil
+	}
+
+	var carriedOver float64
+	previous := calendar.FiscalPeriod(period.Start.Year()-1, period.Start.Month())
+	if cfg.Allowance.CarryOverCap > 0 && len(period.Months()) == 12 &&
+		s.storage.IsYearDataExists(previous.Start.Year()) {
+		previousBalance, err := s.vacationBalance(previous, cfg, ctx, cache)
+		if err != nil {
+			return nil, err
+		}
+		carriedOver = allowance.CarryOver(cfg.Allowance, *previousBalance)
+	}
+
+	dataConfig, err := s.LoadCategoryByPeriodWithGenerated(period, cfg, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := allowance.Compute(cfg.Allowance, period, dataConfig, ctx, carriedOver, entity.Today())
+	cache[period.Start] = balance
+
+	return &balance, nil
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+	"github.com/nsr888/lifecalendar/internal/ical"
+	"github.com/nsr888/lifecalendar/internal/storage"
+)
+
+// RunExportICS prints every entry of the configured years as an iCalendar
+// document. When categories is not empty only those categories are exported.
+func (s *Service) RunExportICS(cfg *config.Config, categories []string) error {
+	events, err := s.collectICSEvents(cfg, categories)
+	if err != nil {
+		return err
+	}
+
+	if err = ical.Encode(os.Stdout, events, time.Now()); err != nil {
+		return fmt.Errorf("failed to export calendar: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) collectICSEvents(
+	cfg *config.Config,
+	categories []string,
+) ([]ical.Event, error) {
+	filter := make(map[string]struct{}, len(categories))
+	for _, category := range categories {
+		filter[category] = struct{}{}
+	}
+
+	var events []ical.Event
+	seen := make(map[string]struct{})
+
+	for _, year := range cfg.Years {
+		if !s.storage.IsYearDataExists(year) {
+			return nil, &storage.DataError{
+				Err: fmt.Errorf("data for year does not exist: %d", year),
+			}
+		}
+
+		dataConfig, err := s.storage.LoadCategoryByYear(year)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to load data config for year %d: %w",
+				year,
+				err,
+			)
+		}
+
+		for categoryName, category := range dataConfig.Categories {
+			if _, ok := filter[categoryName]; len(filter) > 0 && !ok {
+				continue
+			}
+
+			occurrences := make(map[string]int)
+			for _, entry := range category.Entries {
+				uid := eventUID(categoryName, entry, occurrences)
+
+				// Entries crossing a year boundary are loaded for both years.
+				if _, exists := seen[uid]; exists {
+					continue
+				}
+				seen[uid] = struct{}{}
+
+				summary := entry.Label
+				if summary == "" || summary == "Event" {
+					summary = strings.ReplaceAll(categoryName, "_", " ")
+				}
+
+				events = append(events, ical.Event{
+					UID:        uid,
+					Summary:    summary,
+					Categories: []string{categoryName},
+					DateStart:  entry.DateStart,
+					DateEnd:    entry.DateEnd,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].DateStart.Equal(events[j].DateStart) {
+			return events[i].DateStart.Before(events[j].DateStart)
+		}
+		return events[i].UID < events[j].UID
+	})
+
+	return events, nil
+}
+
+// eventUID derives a stable UID from the category and the entry's dates, so
+// re-importing an export updates events instead of duplicating them. Entries
+// with identical dates in one category are told apart by their occurrence.
+func eventUID(
+	categoryName string,
+	entry entity.CategoryEntry,
+	occurrences map[string]int,
+) string {
+	key := fmt.Sprintf(
+		"%s|%s|%s",
+		categoryName,
+		entry.DateStart.Format("2006-01-02"),
+		entry.DateEnd.Format("2006-01-02"),
+	)
+
+	occurrences[key]++
+	if occurrences[key] > 1 {
+		key = fmt.Sprintf("%s|%d", key, occurrences[key])
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:12]) + "@lifecalendar"
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/calendar"
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+// generateRotation returns the days of the year that are active in the
+// generator's cycle.
+func generateRotation(year int, generator config.Generator) map[entity.Date]struct{} {
+	days := make(map[entity.Date]struct{})
+
+	// The generator was validated when the config was loaded.
+	length, _ := generator.Cycle()
+	anchor, _ := generator.AnchorDate()
+
+	active := make(map[int]struct{})
+	for _, day := range activeDays(generator) {
+		active[day] = struct{}{}
+	}
+
+	start := entity.NewDate(year, time.January, 1)
+	for date := start; date.Year() == year; date = date.AddDate(0, 0, 1) {
+		var offset int
+		if anchor.IsZero() {
+			_, week := date.ISOWeek()
+			offset = (week-1)%generator.CycleWeeks*7 + calendar.WeekdayIndex(date)
+		} else {
+			offset = (date.DaysSince(anchor)%length + length) % length
+		}
+
+		if _, isActive := active[offset]; isActive {
+			days[date] = struct{}{}
+		}
+	}
+
+	return days
+}
+
+// activeDays returns the configured active days, or the first week of a
+// weekly cycle and the first day of a daily one.
+func activeDays(generator config.Generator) []int {
+	if len(generator.ActiveDays) > 0 {
+		return generator.ActiveDays
+	}
+
+	if generator.CycleWeeks > 0 {
+		return []int{0, 1, 2, 3, 4, 5, 6}
+	}
+
+	return []int{0}
+}
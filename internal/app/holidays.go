@@ -0,0 +1,100 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+	"github.com/nsr888/lifecalendar/internal/holidays"
+)
+
+const publicHolidaysCategory = "public_holidays"
+
+// leaveCategories are the categories taking working days off.
+var leaveCategories = []string{"vacations", "personal_days"}
+
+// loadCategoryByYearWithHolidays loads the stored categories of the year and
+// adds the public holidays generated for the configured country. A year
+// without data starts out with no stored categories.
+func (s *Service) loadCategoryByYearWithHolidays(
+	year int,
+	cfg *config.Config,
+) (*entity.CategoryName, error) {
+	dataConfig := &entity.CategoryName{
+		BaseYear:   year,
+		Categories: make(map[string]*entity.Category),
+	}
+
+	if s.storage.IsYearDataExists(year) {
+		var err error
+		dataConfig, err = s.storage.LoadCategoryByYear(year)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to load data config for year %d: %w",
+				year,
+				err,
+			)
+		}
+	}
+
+	if cfg.Holidays.Country == "" {
+		return dataConfig, nil
+	}
+
+	generated, err := holidays.Generate(cfg.Holidays.Country, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate public holidays for %d: %w", year, err)
+	}
+
+	dataConfig.Categories[publicHolidaysCategory] = mergeHolidays(
+		dataConfig.Categories[publicHolidaysCategory],
+		generated,
+	)
+
+	return dataConfig, nil
+}
+
+// mergeHolidays combines hand-written holiday entries with generated ones.
+// Hand-written entries win: a generated holiday on a stored date, or named
+// like a stored entry, is dropped, so a CSV row can rename a holiday on its
+// date or move it to another one under the same name.
+func mergeHolidays(
+	stored *entity.Category,
+	generated []entity.CategoryEntry,
+) *entity.Category {
+	merged := &entity.Category{
+		Type:  entity.CategoryType(publicHolidaysCategory),
+		Desc:  publicHolidaysCategory,
+		Dates: make(map[entity.Date]struct{}),
+	}
+
+	storedLabels := make(map[string]struct{})
+	if stored != nil {
+		merged.Type = stored.Type
+		merged.Desc = stored.Desc
+		merged.Entries = append(merged.Entries, stored.Entries...)
+		maps.Copy(merged.Dates, stored.Dates)
+		merged.Portions = maps.Clone(stored.Portions)
+
+		for _, entry := range stored.Entries {
+			if label := entryLabel(entry); label != "" {
+				storedLabels[strings.ToLower(label)] = struct{}{}
+			}
+		}
+	}
+
+	for _, entry := range generated {
+		if _, overridden := merged.Dates[entry.DateStart]; overridden {
+			continue
+		}
+		if _, moved := storedLabels[strings.ToLower(entry.Label)]; moved {
+			continue
+		}
+		merged.Entries = append(merged.Entries, entry)
+		merged.Dates[entry.DateStart] = struct{}{}
+	}
+
+	return merged
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+	"github.com/nsr888/lifecalendar/internal/ical"
+)
+
+// RunImportICS adds the events of an iCalendar file to a category. Single
+// events are imported at their own dates and recurring events are expanded
+// within the configured years. Every occurrence is split at year boundaries
+// and rows that already exist are skipped, so importing the same file twice
+// is safe.
+func (s *Service) RunImportICS(cfg *config.Config, filename, category string) error {
+	if category == "" {
+		return fmt.Errorf("a target category is required")
+	}
+	if len(cfg.Years) == 0 {
+		return fmt.Errorf("no years configured")
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	events, err := ical.Decode(file)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	from := entity.NewDate(slices.Min(cfg.Years), time.January, 1)
+	to := entity.NewDate(slices.Max(cfg.Years), time.December, 31)
+
+	existing := make(map[int]map[string]struct{})
+	var imported, skipped int
+
+	for _, event := range events {
+		if event.Recurrence != nil && len(event.Recurrence.Unsupported) > 0 {
+			s.logger.Printf(
+				"Event %q uses unsupported RRULE parts %v, importing its first occurrence only",
+				event.Summary,
+				event.Recurrence.Unsupported,
+			)
+		}
+
+		occurrences := []ical.Event{event}
+		if event.Recurrence != nil && len(event.Recurrence.Unsupported) == 0 {
+			occurrences = event.Occurrences(from, to)
+		}
+
+		for _, occurrence := range occurrences {
+			occurrenceEntry := entity.CategoryEntry{
+				DateStart: occurrence.DateStart,
+				DateEnd:   occurrence.DateEnd,
+				Label:     occurrence.Summary,
+			}
+			for _, entry := range occurrenceEntry.SplitByYear() {
+				year := entry.DateStart.Year()
+
+				present, loadErr := s.existingEntryKeys(existing, year, category)
+				if loadErr != nil {
+					return loadErr
+				}
+
+				key := entryKey(entry)
+				if _, exists := present[key]; exists {
+					skipped++
+					continue
+				}
+
+				if addErr := s.storage.AddEntry(year, category, entry); addErr != nil {
+					return fmt.Errorf("failed to add entry to %s/%d: %w", category, year, addErr)
+				}
+				present[key] = struct{}{}
+				imported++
+			}
+		}
+	}
+
+	s.logger.Printf(
+		"Imported %d entries into %s, skipped %d already present",
+		imported,
+		category,
+		skipped,
+	)
+
+	return nil
+}
+
+// existingEntryKeys returns the keys of the category's entries for the year,
+// loading them on first use.
+func (s *Service) existingEntryKeys(
+	cache map[int]map[string]struct{},
+	year int,
+	category string,
+) (map[string]struct{}, error) {
+	if keys, exists := cache[year]; exists {
+		return keys, nil
+	}
+
+	keys := make(map[string]struct{})
+	cache[year] = keys
+
+	if !s.storage.IsYearDataExists(year) {
+		return keys, nil
+	}
+
+	dataConfig, err := s.storage.LoadCategoryByYear(year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data config for year %d: %w", year, err)
+	}
+
+	if existingCategory, exists := dataConfig.Categories[category]; exists {
+		for _, entry := range existingCategory.Entries {
+			keys[entryKey(entry)] = struct{}{}
+		}
+	}
+
+	return keys, nil
+}
+
+// entryKey identifies an entry by its dates and label. Empty labels are
+// loaded as "Event", so both spellings share a key.
+func entryKey(entry entity.CategoryEntry) string {
+	label := entry.Label
+	if label == "" {
+		label = "Event"
+	}
+
+	return fmt.Sprintf(
+		"%s|%s|%s",
+		entry.DateStart.Format("2006-01-02"),
+		entry.DateEnd.Format("2006-01-02"),
+		label,
+	)
+}
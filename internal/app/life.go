@@ -0,0 +1,123 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/nsr888/lifecalendar/internal/calendar"
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+	"github.com/nsr888/lifecalendar/internal/render"
+	"github.com/nsr888/lifecalendar/internal/storage"
+	"github.com/nsr888/lifecalendar/internal/styles"
+)
+
+// renderLife renders the "life in weeks" calendar from every year with data,
+// with the labeled entries as milestones.
+func (s *Service) renderLife(cfg *config.Config, ctx *entity.RenderContext) error {
+	birth, err := cfg.Life.Birth()
+	if err != nil {
+		return fmt.Errorf("failed to render life calendar: %w", err)
+	}
+
+	years, err := s.storage.ListYears()
+	if err != nil {
+		return fmt.Errorf("failed to list years: %w", err)
+	}
+
+	if err = s.preload(years); err != nil {
+		return err
+	}
+
+	dayIndex, err := s.lifeDayStyles(cfg, years)
+	if err != nil {
+		return err
+	}
+
+	milestones, err := s.lifeMilestones(years, birth, cfg.Life.LifeExpectancy)
+	if err != nil {
+		return err
+	}
+
+	life := entity.LifeCalendar{
+		Birth:      birth,
+		Years:      calendar.LifeWeeks(birth, cfg.Life.LifeExpectancy, dayIndex),
+		Milestones: milestones,
+	}
+
+	period := entity.Period{
+		Start: birth,
+		End:   birth.AddDate(cfg.Life.LifeExpectancy, 0, -1),
+		Title: "Life in weeks",
+	}
+
+	styleService := styles.NewService(cfg, s.storage, dayIndex)
+	renderService := render.NewService(period, nil, cfg, ctx, styleService)
+	renderService.SetMaxWidth(cfg.Rendering.MaxWidthInChars)
+	renderService.RenderTitle()
+	renderService.RenderLifeView(life)
+
+	return nil
+}
+
+// lifeDayStyles picks the category of every day of the years with data.
+// Generated categories like weekends are left out, so only what was
+// recorded colours a week.
+func (s *Service) lifeDayStyles(
+	cfg *config.Config,
+	years []int,
+) (*entity.DayIndex, error) {
+	dayIndex := &entity.DayIndex{}
+
+	for _, year := range years {
+		dataConfig, err := s.loadCategoryByYearWithHolidays(year, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		yearIndex, err := styles.ComputePeriodStyles(cfg, calendar.YearPeriod(year), dataConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute day styles for year %d: %w", year, err)
+		}
+		dayIndex.Add(yearIndex)
+	}
+
+	return dayIndex, nil
+}
+
+// lifeMilestones returns the labels of the labeled entries by the year of
+// age they start in.
+func (s *Service) lifeMilestones(
+	years []int,
+	birth entity.Date,
+	lifeExpectancy int,
+) (map[int][]string, error) {
+	milestones := make(map[int][]string)
+	seen := make(map[storage.CategoryEntry]struct{})
+
+	for _, year := range years {
+		labeledCategories, err := s.storage.LoadLabeledCategories(year)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to load labeled categories for year %d: %w",
+				year,
+				err,
+			)
+		}
+
+		for _, category := range labeledCategories {
+			for _, entry := range category.Entries {
+				if _, exists := seen[entry]; exists || entry.DateStart.Before(birth) {
+					continue
+				}
+				seen[entry] = struct{}{}
+
+				age := calendar.AgeAt(birth, entry.DateStart)
+				if age < lifeExpectancy {
+					milestones[age] = append(milestones[age], entry.Label)
+				}
+			}
+		}
+	}
+
+	return milestones, nil
+}
@@ -0,0 +1,301 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nsr888/lifecalendar/internal/calendar"
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+// Output formats of RunList.
+const (
+	ListFormatTable = "table"
+	ListFormatCSV   = "csv"
+	ListFormatJSON  = "json"
+)
+
+// ListFilter selects the entries printed by RunList. Zero fields match
+// every entry.
+type ListFilter struct {
+	Categories []string
+	Label      string         // case-insensitive substring of the label
+	Match      *regexp.Regexp // matched against the label
+	From, To   entity.Date    // entries sharing a day with [From, To]
+	MinDays    float64        // minimum total days, half days count 0.5
+	Years      []int          // year folders to search, default: all
+}
+
+// RunList prints the entries matching the filter across years, ordered by
+// date, with the working days each takes off.
+func (s *Service) RunList(cfg *config.Config, filter ListFilter, format string) error {
+	entries, err := s.findEntries(filter)
+	if err != nil {
+		return err
+	}
+
+	listed, err := s.listedEntries(cfg, entries)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case ListFormatCSV:
+		return writeListCSV(os.Stdout, listed)
+	case ListFormatJSON:
+		jsonData, marshalErr := json.Marshal(listed)
+		if marshalErr != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", marshalErr)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	default:
+		return writeListTable(os.Stdout, listed)
+	}
+}
+
+// categoryEntry is an entry together with the category it belongs to.
+type categoryEntry struct {
+	category string
+	entry    entity.CategoryEntry
+}
+
+// findEntries loads the entries matching the filter. Entries crossing a year
+// boundary are loaded with both years and kept once.
+func (s *Service) findEntries(filter ListFilter) ([]categoryEntry, error) {
+	years, err := s.listYears(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.preload(years); err != nil {
+		return nil, err
+	}
+
+	var found []categoryEntry
+	seen := make(map[categoryEntry]struct{})
+
+	for _, year := range years {
+		dataConfig, loadErr := s.storage.LoadCategoryByYear(year)
+		if loadErr != nil {
+			return nil, fmt.Errorf("failed to load data config for year %d: %w", year, loadErr)
+		}
+
+		for name, category := range dataConfig.Categories {
+			if len(filter.Categories) > 0 && !slices.Contains(filter.Categories, name) {
+				continue
+			}
+
+			for _, entry := range category.Entries {
+				candidate := categoryEntry{category: name, entry: entry}
+				if _, duplicate := seen[candidate]; duplicate || !filter.matches(entry) {
+					continue
+				}
+				seen[candidate] = struct{}{}
+				found = append(found, candidate)
+			}
+		}
+	}
+
+	slices.SortFunc(found, func(a, b categoryEntry) int {
+		if c := a.entry.DateStart.Compare(b.entry.DateStart); c != 0 {
+			return c
+		}
+		return strings.Compare(a.category, b.category)
+	})
+
+	return found, nil
+}
+
+// listYears returns the years with data the filter can match.
+func (s *Service) listYears(filter ListFilter) ([]int, error) {
+	years := filter.Years
+	if len(years) == 0 {
+		stored, err := s.storage.ListYears()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list years: %w", err)
+		}
+		years = stored
+	}
+
+	var result []int
+	for _, year := range years {
+		// Entries of the adjacent years that overlap are loaded with the year.
+		if !filter.From.IsZero() && year < filter.From.Year() ||
+			!filter.To.IsZero() && year > filter.To.Year() {
+			continue
+		}
+		if s.storage.IsYearDataExists(year) {
+			result = append(result, year)
+		}
+	}
+
+	return result, nil
+}
+
+func (f ListFilter) matches(entry entity.CategoryEntry) bool {
+	if !f.From.IsZero() && entry.DateEnd.Before(f.From) ||
+		!f.To.IsZero() && entry.DateStart.After(f.To) {
+		return false
+	}
+
+	label := entryLabel(entry)
+	if f.Label != "" && !strings.Contains(strings.ToLower(label), strings.ToLower(f.Label)) {
+		return false
+	}
+
+	if f.Match != nil && !f.Match.MatchString(label) {
+		return false
+	}
+
+	return entryTotalDays(entry) >= f.MinDays
+}
+
+// entryLabel returns the label of the entry, empty for unlabeled entries
+// loaded with the "Event" placeholder.
+func entryLabel(entry entity.CategoryEntry) string {
+	if entry.Label == "Event" {
+		return ""
+	}
+
+	return entry.Label
+}
+
+func entryTotalDays(entry entity.CategoryEntry) float64 {
+	return float64(entry.DateEnd.DaysSince(entry.DateStart)+1) * entry.Portion.Fraction()
+}
+
+// listedEntries computes the day counts of the entries. Public holidays,
+// stored or generated, are collected from every year the entries touch.
+func (s *Service) listedEntries(
+	cfg *config.Config,
+	entries []categoryEntry,
+) ([]entity.ListedEntry, error) {
+	ctx := newRenderContext(cfg)
+	holidays := &entity.Category{}
+	loadedYears := make(map[int]struct{})
+
+	listed := make([]entity.ListedEntry, 0, len(entries))
+	for _, found := range entries {
+		entry := found.entry
+
+		for year := entry.DateStart.Year(); year <= entry.DateEnd.Year(); year++ {
+			if _, loaded := loadedYears[year]; loaded {
+				continue
+			}
+			loadedYears[year] = struct{}{}
+
+			if err := s.addYearHolidays(holidays, year, cfg); err != nil {
+				return nil, err
+			}
+		}
+
+		listed = append(listed, entity.ListedEntry{
+			Category:    found.category,
+			DateStart:   entry.DateStart.Format("2006-01-02"),
+			DateEnd:     entry.DateEnd.Format("2006-01-02"),
+			Label:       entryLabel(entry),
+			Portion:     string(entry.Portion),
+			TotalDays:   entryTotalDays(entry),
+			WorkingDays: calendar.WorkingDays(entry, holidays, ctx),
+		})
+	}
+
+	return listed, nil
+}
+
+// addYearHolidays adds the public holidays of the year to holidays.
+func (s *Service) addYearHolidays(holidays *entity.Category, year int, cfg *config.Config) error {
+	dataConfig, err := s.loadCategoryByYearWithHolidays(year, cfg)
+	if err != nil {
+		return err
+	}
+
+	yearHolidays, exists := dataConfig.Categories[publicHolidaysCategory]
+	if !exists {
+		return nil
+	}
+
+	for date := range yearHolidays.Dates {
+		portion, _ := yearHolidays.Portion(date)
+		holidays.AddDay(date, portion)
+	}
+
+	return nil
+}
+
+var listColumns = []string{
+	"category", "date_start", "date_end", "label", "portion", "total_days", "working_days",
+}
+
+func listRecord(entry entity.ListedEntry) []string {
+	return []string{
+		entry.Category,
+		entry.DateStart,
+		entry.DateEnd,
+		entry.Label,
+		entry.Portion,
+		formatDays(entry.TotalDays),
+		formatDays(entry.WorkingDays),
+	}
+}
+
+func formatDays(days float64) string {
+	return strconv.FormatFloat(days, 'f', -1, 64)
+}
+
+func writeListCSV(w io.Writer, listed []entity.ListedEntry) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(listColumns); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, entry := range listed {
+		if err := writer.Write(listRecord(entry)); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return nil
+}
+
+// writeListTable writes the entries as aligned columns with a totals line.
+func writeListTable(w io.Writer, listed []entity.ListedEntry) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	headers := make([]string, len(listColumns))
+	for i, column := range listColumns {
+		headers[i] = strings.ToUpper(strings.ReplaceAll(column, "_", " "))
+	}
+	fmt.Fprintln(table, strings.Join(headers, "\t"))
+
+	var totalDays, workingDays float64
+	for _, entry := range listed {
+		fmt.Fprintln(table, strings.Join(listRecord(entry), "\t"))
+		totalDays += entry.TotalDays
+		workingDays += entry.WorkingDays
+	}
+
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("failed to write table: %w", err)
+	}
+
+	_, err := fmt.Fprintf(w, "%d entries, %s days, %s working days\n",
+		len(listed), formatDays(totalDays), formatDays(workingDays))
+
+	return err
+}
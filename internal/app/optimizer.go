@@ -0,0 +1,60 @@
+package app
+
+import (
+	"math"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+	"github.com/nsr888/lifecalendar/internal/optimizer"
+)
+
+// optimizeVacations places the vacation budget on the rest of the period, from
+// tomorrow on, around weekends and holidays and away from booked days. The
+// budget defaults to the remaining allowance; without either it returns nil.
+func optimizeVacations(
+	period entity.Period,
+	cfg *config.Config,
+	ctx *entity.RenderContext,
+	holidays map[entity.Date]struct{},
+	bookedDays map[entity.Date]struct{},
+	balance *entity.VacationBalance,
+) *entity.VacationOptimization {
+	budget := cfg.Optimizer.Budget
+	if budget == 0 && balance != nil {
+		budget = int(math.Max(0, math.Floor(balance.Remaining)))
+	}
+	if budget == 0 {
+		return nil
+	}
+
+	from := entity.Today().AddDate(0, 0, 1)
+	if from.Before(period.Start) {
+		from = period.Start
+	}
+
+	var days []optimizer.Day
+	for cur := from; !cur.After(period.End); cur = cur.AddDate(0, 0, 1) {
+		_, isHoliday := holidays[cur]
+		_, isBooked := bookedDays[cur]
+		days = append(days, optimizer.Day{
+			Date:    cur,
+			Off:     !ctx.IsWorkingDay(cur) || isHoliday,
+			Blocked: isBooked,
+		})
+	}
+
+	avoidMonths := make([]time.Month, 0, len(cfg.Optimizer.AvoidMonths))
+	for _, month := range cfg.Optimizer.AvoidMonths {
+		avoidMonths = append(avoidMonths, time.Month(month))
+	}
+
+	optimization := optimizer.Optimize(days, optimizer.Constraints{
+		Budget:       budget,
+		AvoidMonths:  avoidMonths,
+		MinBlockDays: cfg.Optimizer.MinBlockDays,
+		MaxBlocks:    cfg.Optimizer.MaxBlocks,
+	})
+
+	return &optimization
+}
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/calendar"
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+	"github.com/nsr888/lifecalendar/internal/storage"
+)
+
+// periods returns the windows to render and count: one per configured year
+// for calendar and fiscal years, or the single custom range.
+func periods(cfg *config.Config) []entity.Period {
+	if cfg.Period.Type == config.PeriodCustom {
+		// The range was validated when the config was loaded.
+		from, to, _ := cfg.Period.Range()
+		return []entity.Period{calendar.CustomPeriod(from, to)}
+	}
+
+	result := make([]entity.Period, 0, len(cfg.Years))
+	for _, year := range cfg.Years {
+		if cfg.Period.Type == config.PeriodFiscal {
+			result = append(result, calendar.FiscalPeriod(year, time.Month(cfg.Period.StartMonth)))
+		} else {
+			result = append(result, calendar.YearPeriod(year))
+		}
+	}
+
+	return result
+}
+
+// periodYears returns the years the periods touch, in ascending order.
+func periodYears(cfg *config.Config) []int {
+	var years []int
+	for _, period := range periods(cfg) {
+		for year := period.Start.Year(); year <= period.End.Year(); year++ {
+			years = append(years, year)
+		}
+	}
+
+	slices.Sort(years)
+
+	return slices.Compact(years)
+}
+
+// preload loads the years ahead when the storage caches them, so years are
+// read concurrently instead of one by one while rendering.
+func (s *Service) preload(years []int) error {
+	preloader, ok := s.storage.(storage.Preloader)
+	if !ok {
+		return nil
+	}
+
+	if err := preloader.Preload(years); err != nil {
+		return fmt.Errorf("failed to load data: %w", err)
+	}
+
+	return nil
+}
+
+// LoadCategoryByPeriodWithGenerated merges the stored and generated categories
+// of every year the period touches, keeping only the days inside the period.
+// Years without data only contribute generated categories.
+func (s *Service) LoadCategoryByPeriodWithGenerated(
+	period entity.Period,
+	cfg *config.Config,
+	ctx *entity.RenderContext,
+) (*entity.CategoryName, error) {
+	merged := &entity.CategoryName{
+		BaseYear:   period.Start.Year(),
+		Categories: make(map[string]*entity.Category),
+	}
+
+	hasData := false
+	for _, year := range period.Years() {
+		dataConfig, err := s.loadCategoryByYearWithHolidays(year, cfg)
+		if err != nil {
+			return nil, err
+		}
+		addGeneratedCategories(dataConfig, year, cfg, ctx)
+
+		hasData = hasData || s.storage.IsYearDataExists(year)
+		mergeCategories(merged, dataConfig, period)
+	}
+
+	if !hasData {
+		return nil, &storage.DataError{
+			Err: fmt.Errorf("data for period does not exist: %s", period.Title),
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeCategories adds the days and entries of src that fall into the
+// period to dst. Entries spanning two years are loaded for both, so
+// duplicates are skipped.
+func mergeCategories(dst, src *entity.CategoryName, period entity.Period) {
+	for name, category := range src.Categories {
+		target, exists := dst.Categories[name]
+		if !exists {
+			target = &entity.Category{
+				Type:  category.Type,
+				Desc:  category.Desc,
+				Dates: make(map[entity.Date]struct{}),
+			}
+			dst.Categories[name] = target
+		}
+
+		for date := range category.Dates {
+			if period.Contains(date) {
+				portion, _ := category.Portion(date)
+				target.AddDay(date, portion)
+			}
+		}
+
+		for _, entry := range category.Entries {
+			if entry.Overlaps(period.Start, period.End) && !containsEntry(target.Entries, entry) {
+				target.Entries = append(target.Entries, entry)
+			}
+		}
+	}
+}
+
+func containsEntry(entries []entity.CategoryEntry, entry entity.CategoryEntry) bool {
+	for _, existing := range entries {
+		if existing.DateStart.Equal(entry.DateStart) &&
+			existing.DateEnd.Equal(entry.DateEnd) &&
+			existing.Label == entry.Label &&
+			existing.Portion == entry.Portion {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadLabeledCategoriesForPeriod collects the labeled entries of every year
+// the period touches that overlap the period, sorted by category name.
+func (s *Service) loadLabeledCategoriesForPeriod(
+	period entity.Period,
+) ([]storage.LabeledCategory, error) {
+	var result []storage.LabeledCategory
+	index := make(map[string]int)
+
+	for _, year := range period.Years() {
+		if !s.storage.IsYearDataExists(year) {
+			continue
+		}
+
+		labeledCategories, err := s.storage.LoadLabeledCategories(year)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to load labeled categories for year %d: %w",
+				year,
+				err,
+			)
+		}
+
+		for _, category := range labeledCategories {
+			i, exists := index[category.Name]
+			if !exists {
+				i = len(result)
+				index[category.Name] = i
+				result = append(result, storage.LabeledCategory{
+					Name:        category.Name,
+					Description: category.Description,
+				})
+			}
+
+			for _, entry := range category.Entries {
+				if !entry.DateStart.After(period.End) && !entry.DateEnd.Before(period.Start) &&
+					!containsLabeledEntry(result[i].Entries, entry) {
+					result[i].Entries = append(result[i].Entries, entry)
+				}
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return slices.DeleteFunc(result, func(category storage.LabeledCategory) bool {
+		return len(category.Entries) == 0
+	}), nil
+}
+
+func containsLabeledEntry(entries []storage.CategoryEntry, entry storage.CategoryEntry) bool {
+	for _, existing := range entries {
+		if existing == entry {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clipToPeriod limits the date range to the days inside the period.
+func clipToPeriod(start, end entity.Date, period entity.Period) (entity.Date, entity.Date) {
+	if start.Before(period.Start) {
+		start = period.Start
+	}
+	if end.After(period.End) {
+		end = period.End
+	}
+
+	return start, end
+}
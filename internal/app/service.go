@@ -4,10 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"maps"
 	"time"
 
 	"github.com/nsr888/lifecalendar/internal/ai"
+	"github.com/nsr888/lifecalendar/internal/calendar"
 	"github.com/nsr888/lifecalendar/internal/config"
 	"github.com/nsr888/lifecalendar/internal/entity"
 	"github.com/nsr888/lifecalendar/internal/render"
@@ -33,68 +33,127 @@ func NewService(storage storage.Storage, logger Logger) *Service {
 }
 
 func (s *Service) Run(initialConfig *config.Config) error {
-	allDayStyles, err := s.computeAllDayStyles(initialConfig)
+	ctx := newRenderContext(initialConfig)
+
+	if initialConfig.Rendering.Format == config.FormatLife {
+		return s.renderLife(initialConfig, ctx)
+	}
+
+	if err := s.preload(periodYears(initialConfig)); err != nil {
+		return err
+	}
+
+	dayIndex, err := s.computeAllDayStyles(initialConfig, ctx)
 	if err != nil {
 		return fmt.Errorf("failed to compute day styles: %w", err)
 	}
 
-	styleService := styles.NewService(initialConfig, s.storage, allDayStyles)
+	styleService := styles.NewService(initialConfig, s.storage, dayIndex)
 
-	return s.renderAllYears(initialConfig, styleService)
+	return s.renderAllPeriods(initialConfig, ctx, styleService)
+}
+
+// newRenderContext builds the weekday, weekend and work schedule definition
+// shared by all calendar computations from the config.
+func newRenderContext(cfg *config.Config) *entity.RenderContext {
+	ctx := calendar.NewRenderContext(
+		cfg.Rendering.FirstWeekday,
+		cfg.Rendering.WeekendDays,
+	)
+
+	for _, schedule := range cfg.Schedules {
+		// Dates were validated when the config was loaded.
+		from, to, anchor, _ := schedule.Dates()
+		ctx.Schedules = append(
+			ctx.Schedules,
+			calendar.NewWorkSchedule(from, to, anchor, schedule.Weeks),
+		)
+	}
+
+	return ctx
 }
 
 func (s *Service) computeAllDayStyles(
 	cfg *config.Config,
-) (map[time.Time]entity.DayInfo, error) {
-	allDayStyles := make(map[time.Time]entity.DayInfo)
+	ctx *entity.RenderContext,
+) (*entity.DayIndex, error) {
+	dayIndex := &entity.DayIndex{}
 
-	for _, year := range cfg.Years {
-		dayStyles, err := s.computeDayStylesForYear(year, cfg)
+	for _, period := range periods(cfg) {
+		dataConfig, err := s.LoadCategoryByPeriodWithGenerated(period, cfg, ctx)
 		if err != nil {
 			return nil, fmt.Errorf(
-				"failed to compute day styles for year %d: %w",
-				year,
+				"failed to compute day styles for %s: %w",
+				period.Title,
 				err,
 			)
 		}
-		maps.Copy(allDayStyles, dayStyles)
+
+		periodIndex, err := styles.ComputePeriodStyles(cfg, period, dataConfig)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to compute day styles for %s: %w",
+				period.Title,
+				err,
+			)
+		}
+		dayIndex.Add(periodIndex)
 	}
 
-	return allDayStyles, nil
+	return dayIndex, nil
 }
 
 func (s *Service) LoadCategoryByYearWithGenerated(
 	year int,
+	cfg *config.Config,
+	ctx *entity.RenderContext,
 ) (*entity.CategoryName, error) {
 	if !s.storage.IsYearDataExists(year) {
-		return nil, fmt.Errorf("data for year does not exist: %d", year)
+		return nil, &storage.DataError{Err: fmt.Errorf("data for year does not exist: %d", year)}
 	}
 
-	dataConfig, err := s.storage.LoadCategoryByYear(year)
+	dataConfig, err := s.loadCategoryByYearWithHolidays(year, cfg)
 	if err != nil {
-		return nil, fmt.Errorf(
-			"failed to load data config for year %d: %w",
-			year,
-			err,
-		)
+		return nil, err
 	}
 
-	weekendDays := generateWeekendDays(year)
+	addGeneratedCategories(dataConfig, year, cfg, ctx)
+
+	return dataConfig, nil
+}
+
+// addGeneratedCategories adds the categories computed from the calendar
+// rather than stored: weekends, non-working days, rotations and today.
+func addGeneratedCategories(
+	dataConfig *entity.CategoryName,
+	year int,
+	cfg *config.Config,
+	ctx *entity.RenderContext,
+) {
+	weekendDays := generateWeekendDays(year, ctx)
 	dataConfig.Categories["weekends"] = &entity.Category{
 		Type:  entity.CategoryWeekends,
 		Dates: weekendDays,
 	}
 
-	oddWeeks := generateOddWeeks(year)
-	dataConfig.Categories["odd_week"] = &entity.Category{
-		Type:  entity.CategoryType("odd_week"),
-		Dates: oddWeeks,
+	nonWorkingDays := generateNonWorkingDays(year, ctx)
+	if len(nonWorkingDays) > 0 {
+		dataConfig.Categories[string(entity.CategoryNonWorkingDays)] = &entity.Category{
+			Type:  entity.CategoryNonWorkingDays,
+			Dates: nonWorkingDays,
+		}
 	}
 
-	evenWeeks := generateEvenWeeks(year)
-	dataConfig.Categories["even_week"] = &entity.Category{
-		Type:  entity.CategoryType("even_week"),
-		Dates: evenWeeks,
+	for name, generator := range cfg.Generators {
+		desc := generator.Label
+		if desc == "" {
+			desc = name
+		}
+		dataConfig.Categories[name] = &entity.Category{
+			Type:  entity.CategoryType(name),
+			Desc:  desc,
+			Dates: generateRotation(year, generator),
+		}
 	}
 
 	currentDays := generateCurrentDay(year)
@@ -104,49 +163,44 @@ func (s *Service) LoadCategoryByYearWithGenerated(
 			Dates: currentDays,
 		}
 	}
-
-	return dataConfig, nil
-}
-
-func (s *Service) computeDayStylesForYear(
-	year int,
-	cfg *config.Config,
-) (map[time.Time]entity.DayInfo, error) {
-	dataConfig, err := s.LoadCategoryByYearWithGenerated(year)
-	if err != nil {
-		return nil, err
-	}
-
-	return styles.ComputeYearStyles(cfg, year, dataConfig)
 }
 
-func (s *Service) renderAllYears(
+func (s *Service) renderAllPeriods(
 	cfg *config.Config,
+	ctx *entity.RenderContext,
 	styleService styles.StyleService,
 ) error {
-	for _, year := range cfg.Years {
-		dataConfig, err := s.LoadCategoryByYearWithGenerated(year)
+	balances := make(map[entity.Date]entity.VacationBalance)
+
+	for _, period := range periods(cfg) {
+		dataConfig, err := s.LoadCategoryByPeriodWithGenerated(period, cfg, ctx)
 		if err != nil {
 			return fmt.Errorf(
-				"failed to load data config for year %d: %w",
-				year,
+				"failed to load data config for %s: %w",
+				period.Title,
 				err,
 			)
 		}
 
-		renderService := render.NewService(year, dataConfig, cfg, styleService)
-		renderService.SetMaxWidth(cfg.Rendering.MaxWidthInChars)
-
-		labeledCategories, err := s.storage.LoadLabeledCategories(year)
+		balance, err := s.vacationBalance(period, cfg, ctx, balances)
 		if err != nil {
 			return fmt.Errorf(
-				"failed to load labeled categories for year %d: %w",
-				year,
+				"failed to compute vacation balance for %s: %w",
+				period.Title,
 				err,
 			)
 		}
 
-		renderService.RenderYearTitle(year)
+		renderService := render.NewService(period, dataConfig, cfg, ctx, styleService)
+		renderService.SetMaxWidth(cfg.Rendering.MaxWidthInChars)
+		renderService.SetBalance(balance)
+
+		labeledCategories, err := s.loadLabeledCategoriesForPeriod(period)
+		if err != nil {
+			return err
+		}
+
+		renderService.RenderTitle()
 
 		// Choose rendering format based on config
 		switch cfg.Rendering.Format {
@@ -160,45 +214,24 @@ func (s *Service) renderAllYears(
 	return nil
 }
 
-func generateCurrentDay(year int) map[time.Time]struct{} {
-	currentDay := make(map[time.Time]struct{})
-
-	now := time.Now()
-	if now.Year() == year {
-		today := time.Date(
-			now.Year(),
-			now.Month(),
-			now.Day(),
-			0,
-			0,
-			0,
-			0,
-			time.Local,
-		)
+func generateCurrentDay(year int) map[entity.Date]struct{} {
+	currentDay := make(map[entity.Date]struct{})
+
+	if today := entity.Today(); today.Year() == year {
 		currentDay[today] = struct{}{}
 	}
 
 	return currentDay
 }
 
-func generateWeekendDays(year int) map[time.Time]struct{} {
-	weekendDays := make(map[time.Time]struct{})
+func generateWeekendDays(year int, ctx *entity.RenderContext) map[entity.Date]struct{} {
+	weekendDays := make(map[entity.Date]struct{})
 
 	for month := 1; month <= 12; month++ {
 		daysInMonth := daysIn(month, year)
 		for day := 1; day <= daysInMonth; day++ {
-			date := time.Date(
-				year,
-				time.Month(month),
-				day,
-				0,
-				0,
-				0,
-				0,
-				time.Local,
-			)
-			if date.Weekday() == time.Saturday ||
-				date.Weekday() == time.Sunday {
+			date := entity.NewDate(year, time.Month(month), day)
+			if ctx.IsWeekend(date) {
 				weekendDays[date] = struct{}{}
 			}
 		}
@@ -207,76 +240,50 @@ func generateWeekendDays(year int) map[time.Time]struct{} {
 	return weekendDays
 }
 
-func daysIn(month, year int) int {
-	return time.Date(year, time.Month(month+1), 0, 0, 0, 0, 0, time.Local).Day()
-}
+// generateNonWorkingDays returns the days off of the work schedules that are
+// not weekend days, e.g. the free Friday of a four-day week.
+func generateNonWorkingDays(year int, ctx *entity.RenderContext) map[entity.Date]struct{} {
+	days := make(map[entity.Date]struct{})
 
-func generateOddWeeks(year int) map[time.Time]struct{} {
-	oddWeeks := make(map[time.Time]struct{})
-
-	for month := 1; month <= 12; month++ {
-		daysInMonth := daysIn(month, year)
-		for day := 1; day <= daysInMonth; day++ {
-			date := time.Date(
-				year,
-				time.Month(month),
-				day,
-				0,
-				0,
-				0,
-				0,
-				time.Local,
-			)
-			_, week := date.ISOWeek()
-			if week%2 == 1 {
-				oddWeeks[date] = struct{}{}
-			}
+	start := entity.NewDate(year, time.January, 1)
+	for date := start; date.Year() == year; date = date.AddDate(0, 0, 1) {
+		if !ctx.IsWorkingDay(date) && !ctx.IsWeekend(date) {
+			days[date] = struct{}{}
 		}
 	}
 
-	return oddWeeks
+	return days
 }
 
-func generateEvenWeeks(year int) map[time.Time]struct{} {
-	evenWeeks := make(map[time.Time]struct{})
+// generateDaysOff returns all days of the period that are not working days
+// under the work schedule, weekends included.
+func generateDaysOff(period entity.Period, ctx *entity.RenderContext) map[entity.Date]struct{} {
+	days := make(map[entity.Date]struct{})
 
-	for month := 1; month <= 12; month++ {
-		daysInMonth := daysIn(month, year)
-		for day := 1; day <= daysInMonth; day++ {
-			date := time.Date(
-				year,
-				time.Month(month),
-				day,
-				0,
-				0,
-				0,
-				0,
-				time.Local,
-			)
-			_, week := date.ISOWeek()
-			if week%2 == 0 {
-				evenWeeks[date] = struct{}{}
-			}
+	for date := period.Start; !date.After(period.End); date = date.AddDate(0, 0, 1) {
+		if !ctx.IsWorkingDay(date) {
+			days[date] = struct{}{}
 		}
 	}
 
-	return evenWeeks
+	return days
+}
+
+func daysIn(month, year int) int {
+	return entity.NewDate(year, time.Month(month+1), 0).Day()
 }
 
 func (s *Service) countWeekendsAndHolidays(
-	start, end time.Time,
-	holidays map[time.Time]struct{},
+	start, end entity.Date,
+	dayIndex *entity.DayIndex,
+	ctx *entity.RenderContext,
 ) (weekendCount, holidayCount int) {
 	for current := start; !current.After(end); current = current.AddDate(0, 0, 1) {
-		if current.Weekday() == time.Saturday ||
-			current.Weekday() == time.Sunday {
+		if !ctx.IsWorkingDay(current) {
 			weekendCount++
 		}
-
-		if _, isHoliday := holidays[current]; isHoliday {
-			holidayCount++
-		}
 	}
+	holidayCount = dayIndex.CoveredDays(publicHolidaysCategory, start, end)
 	return
 }
 
@@ -296,14 +303,14 @@ func (s *Service) RunJSONPlan(cfg *config.Config) error {
 }
 
 func (s *Service) findConsecutiveWeekendHolidayBlocks(
-	year int,
-	holidays map[time.Time]struct{},
+	period entity.Period,
+	holidays map[entity.Date]struct{},
 	existingPlans []entity.VacationPlanJSON,
+	ctx *entity.RenderContext,
 ) ([]entity.PotentialVacation, error) {
-	nonWorkingDays := make(map[time.Time]bool)
+	nonWorkingDays := make(map[entity.Date]bool)
 
-	weekendDays := generateWeekendDays(year)
-	for date := range weekendDays {
+	for date := range generateDaysOff(period, ctx) {
 		nonWorkingDays[date] = true
 	}
 
@@ -312,11 +319,11 @@ func (s *Service) findConsecutiveWeekendHolidayBlocks(
 	}
 
 	for _, plan := range existingPlans {
-		startDate, err := time.ParseInLocation("2006-01-02", plan.DateStart, time.Local)
+		startDate, err := entity.ParseDate(plan.DateStart)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse start date: %w", err)
 		}
-		endDate, err := time.ParseInLocation("2006-01-02", plan.DateEnd, time.Local)
+		endDate, err := entity.ParseDate(plan.DateEnd)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse end date: %w", err)
 		}
@@ -326,13 +333,10 @@ func (s *Service) findConsecutiveWeekendHolidayBlocks(
 		}
 	}
 
-	startDate := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
-	endDate := time.Date(year, time.December, 31, 0, 0, 0, 0, time.Local)
-
 	var potentialVacations []entity.PotentialVacation
-	var currentSequence []time.Time
+	var currentSequence []entity.Date
 
-	for current := startDate; !current.After(endDate); current = current.AddDate(0, 0, 1) {
+	for current := period.Start; !current.After(period.End); current = current.AddDate(0, 0, 1) {
 		if nonWorkingDays[current] {
 			currentSequence = append(currentSequence, current)
 		} else {
@@ -343,7 +347,7 @@ func (s *Service) findConsecutiveWeekendHolidayBlocks(
 				weekendCount := 0
 				holidayCount := 0
 				for _, date := range currentSequence {
-					if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+					if !ctx.IsWorkingDay(date) {
 						weekendCount++
 					}
 					if _, isHoliday := holidays[date]; isHoliday {
@@ -363,7 +367,7 @@ func (s *Service) findConsecutiveWeekendHolidayBlocks(
 				potentialVacations = append(potentialVacations, potential)
 			}
 
-			currentSequence = []time.Time{}
+			currentSequence = []entity.Date{}
 		}
 	}
 
@@ -373,79 +377,120 @@ func (s *Service) findConsecutiveWeekendHolidayBlocks(
 func (s *Service) generateCalendarJSON(cfg *config.Config) ([]entity.EnhancedJSONPlanResponse, error) {
 	var allPlansWithPotential []entity.EnhancedJSONPlanResponse
 
-	for _, year := range cfg.Years {
-		labeledCategories, err := s.storage.LoadLabeledCategories(year)
+	ctx := newRenderContext(cfg)
+	balances := make(map[entity.Date]entity.VacationBalance)
+
+	if err := s.preload(periodYears(cfg)); err != nil {
+		return nil, err
+	}
+
+	for _, period := range periods(cfg) {
+		response, err := s.generatePeriodJSON(period, cfg, ctx, balances)
 		if err != nil {
-			return nil, fmt.Errorf(
-				"failed to load labeled categories for year %d: %w",
-				year,
-				err,
-			)
+			return nil, err
 		}
 
-		publicHolidays := make(map[time.Time]struct{})
-		dataConfig, err := s.storage.LoadCategoryByYear(year)
-		if err != nil {
-			return nil, fmt.Errorf(
-				"failed to load data config for year %d: %w",
-				year,
-				err,
+		allPlansWithPotential = append(allPlansWithPotential, response)
+	}
+
+	return allPlansWithPotential, nil
+}
+
+func (s *Service) generatePeriodJSON(
+	period entity.Period,
+	cfg *config.Config,
+	ctx *entity.RenderContext,
+	balances map[entity.Date]entity.VacationBalance,
+) (entity.EnhancedJSONPlanResponse, error) {
+	labeledCategories, err := s.loadLabeledCategoriesForPeriod(period)
+	if err != nil {
+		return entity.EnhancedJSONPlanResponse{}, err
+	}
+
+	publicHolidays := make(map[entity.Date]struct{})
+	dataConfig, err := s.LoadCategoryByPeriodWithGenerated(period, cfg, ctx)
+	if err != nil {
+		return entity.EnhancedJSONPlanResponse{}, err
+	}
+	if holidays, exists := dataConfig.Categories[publicHolidaysCategory]; exists {
+		publicHolidays = holidays.Dates
+	}
+	dayIndex := entity.NewDayIndex(dataConfig, period.Start, period.End, cfg.CategoryPriority)
+
+	var allPlans []entity.VacationPlanJSON
+	for _, category := range labeledCategories {
+		for _, entry := range category.Entries {
+			// Entries crossing the period boundary only count their part
+			// inside the period, so they are not counted twice.
+			dateStart, dateEnd := clipToPeriod(entry.DateStart, entry.DateEnd, period)
+
+			weekendCount, holidayCount := s.countWeekendsAndHolidays(
+				dateStart,
+				dateEnd,
+				dayIndex,
+				ctx,
 			)
+
+			totalDays := dateEnd.DaysSince(dateStart) + 1
+
+			plan := entity.VacationPlanJSON{
+				DateStart:    dateStart.Format("2006-01-02"),
+				DateEnd:      dateEnd.Format("2006-01-02"),
+				Label:        entry.Label,
+				Portion:      string(entry.Portion),
+				WeekendCount: weekendCount,
+				HolidayCount: holidayCount,
+				TotalDays:    float64(totalDays) * entry.Portion.Fraction(),
+			}
+
+			allPlans = append(allPlans, plan)
 		}
-		if holidays, exists := dataConfig.Categories["public_holidays"]; exists {
-			publicHolidays = holidays.Dates
-		}
+	}
 
-		var allPlans []entity.VacationPlanJSON
-		for _, category := range labeledCategories {
-			for _, entry := range category.Entries {
-				weekendCount, holidayCount := s.countWeekendsAndHolidays(
-					entry.DateStart,
-					entry.DateEnd,
-					publicHolidays,
-				)
-
-				totalDays := int(
-					entry.DateEnd.Sub(entry.DateStart).Hours()/24,
-				) + 1
-
-				plan := entity.VacationPlanJSON{
-					DateStart:    entry.DateStart.Format("2006-01-02"),
-					DateEnd:      entry.DateEnd.Format("2006-01-02"),
-					Label:        entry.Label,
-					WeekendCount: weekendCount,
-					HolidayCount: holidayCount,
-					TotalDays:    totalDays,
+	// Only leave, labeled or not, keeps the optimizer away; holidays are days
+	// off to bridge, not booked days.
+	bookedDays := make(map[entity.Date]struct{})
+	for _, name := range leaveCategories {
+		if leave, exists := dataConfig.Categories[name]; exists {
+			for date := range leave.Dates {
+				if period.Contains(date) {
+					bookedDays[date] = struct{}{}
 				}
-
-				allPlans = append(allPlans, plan)
 			}
 		}
+	}
 
-		yearPotentialPlans, err := s.findConsecutiveWeekendHolidayBlocks(
-			year,
-			publicHolidays,
-			allPlans,
+	potentialPlans, err := s.findConsecutiveWeekendHolidayBlocks(
+		period,
+		publicHolidays,
+		allPlans,
+		ctx,
+	)
+	if err != nil {
+		return entity.EnhancedJSONPlanResponse{}, fmt.Errorf(
+			"failed to find potential vacation plans for %s: %w",
+			period.Title,
+			err,
 		)
-		if err != nil {
-			return nil, fmt.Errorf(
-				"failed to find potential vacation plans for year %d: %w",
-				year,
-				err,
-			)
-		}
+	}
 
-		allPlansWithPotential = append(
-			allPlansWithPotential,
-			entity.EnhancedJSONPlanResponse{
-				ExistingVacations:  allPlans,
-				PotentialVacations: yearPotentialPlans,
-				Year:               year,
-			},
+	balance, err := s.vacationBalance(period, cfg, ctx, balances)
+	if err != nil {
+		return entity.EnhancedJSONPlanResponse{}, fmt.Errorf(
+			"failed to compute vacation balance for %s: %w",
+			period.Title,
+			err,
 		)
 	}
 
-	return allPlansWithPotential, nil
+	return entity.EnhancedJSONPlanResponse{
+		ExistingVacations:  allPlans,
+		PotentialVacations: potentialPlans,
+		Balance:            balance,
+		Optimization:       optimizeVacations(period, cfg, ctx, publicHolidays, bookedDays, balance),
+		Year:               period.Start.Year(),
+		Period:             period.Title,
+	}, nil
 }
 
 func (s *Service) RunAIReview(cfg *config.Config) error {
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/nsr888/lifecalendar/internal/calendar"
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/entity"
+	"github.com/nsr888/lifecalendar/internal/render"
+	"github.com/nsr888/lifecalendar/internal/storage"
+	"github.com/nsr888/lifecalendar/internal/styles"
+)
+
+// RunTeam renders the team view of every period: one row per person per
+// month, the number of people away each day and the days on which more
+// than the configured maximum are away.
+func (s *Service) RunTeam(cfg *config.Config, people map[string]storage.Storage) error {
+	if len(people) == 0 {
+		return fmt.Errorf("no people configured, add [people.<name>] sections")
+	}
+
+	ctx := newRenderContext(cfg)
+	styleService := styles.NewService(cfg, s.storage, nil)
+
+	for _, period := range periods(cfg) {
+		var members []entity.TeamMember
+		for _, name := range slices.Sorted(maps.Keys(people)) {
+			member, err := s.loadTeamMember(name, people[name], period, cfg, ctx)
+			if err != nil {
+				return err
+			}
+			members = append(members, member)
+		}
+
+		conflicts := calendar.FindTeamConflicts(members, period, cfg.Team.MaxAbsent, ctx)
+
+		renderService := render.NewService(period, nil, cfg, ctx, styleService)
+		renderService.SetMaxWidth(cfg.Rendering.MaxWidthInChars)
+		renderService.RenderTitle()
+		renderService.RenderTeamView(members, conflicts)
+	}
+
+	return nil
+}
+
+// loadTeamMember loads the leave a person booked in the period. Years
+// without data in the person's folder count as no leave.
+func (s *Service) loadTeamMember(
+	name string,
+	personStorage storage.Storage,
+	period entity.Period,
+	cfg *config.Config,
+	ctx *entity.RenderContext,
+) (entity.TeamMember, error) {
+	person := NewService(personStorage, s.logger)
+	data := &entity.CategoryName{
+		BaseYear:   period.Start.Year(),
+		Categories: make(map[string]*entity.Category),
+	}
+
+	for _, year := range period.Years() {
+		dataConfig, err := person.loadCategoryByYearWithHolidays(year, cfg)
+		if err != nil {
+			return entity.TeamMember{}, fmt.Errorf("failed to load data of %s: %w", name, err)
+		}
+		mergeCategories(data, dataConfig, period)
+	}
+
+	return entity.TeamMember{
+		Name:   name,
+		Absent: calendar.LeaveByDay(data, period, ctx),
+	}, nil
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/nsr888/lifecalendar/internal/config"
+	"github.com/nsr888/lifecalendar/internal/storage"
+)
+
+// RunValidate checks the data of every configured year, prints one
+// file:line:column diagnostic per problem and fails if any error was found.
+func (s *Service) RunValidate(cfg *config.Config) error {
+	validator, ok := storage.Unwrap(s.storage).(storage.Validator)
+	if !ok {
+		return errors.New("storage backend does not support validation")
+	}
+
+	var diagnostics []storage.Diagnostic
+	for _, year := range cfg.Years {
+		yearDiagnostics, err := validator.Validate(year)
+		if err != nil {
+			return fmt.Errorf("failed to validate year %d: %w", year, err)
+		}
+		diagnostics = append(diagnostics, yearDiagnostics...)
+
+		diagnostics = append(diagnostics, s.unstyledCategoryDiagnostics(cfg, year)...)
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].File != diagnostics[j].File {
+			return diagnostics[i].File < diagnostics[j].File
+		}
+		if diagnostics[i].Line != diagnostics[j].Line {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+		return diagnostics[i].Column < diagnostics[j].Column
+	})
+
+	var errorCount, warningCount int
+	for _, diagnostic := range diagnostics {
+		fmt.Println(diagnostic.String())
+
+		if diagnostic.Severity == storage.SeverityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+
+	fmt.Printf("%d error(s), %d warning(s)\n", errorCount, warningCount)
+
+	if errorCount > 0 {
+		return &storage.DataError{Err: fmt.Errorf("validation failed with %d error(s)", errorCount)}
+	}
+
+	return nil
+}
+
+// unstyledCategoryDiagnostics warns about categories that have no style in
+// the config and therefore get a generated color.
+func (s *Service) unstyledCategoryDiagnostics(
+	cfg *config.Config,
+	year int,
+) []storage.Diagnostic {
+	if !s.storage.IsYearDataExists(year) {
+		return nil
+	}
+
+	categoryNames, err := s.storage.GetCategoryNames(year)
+	if err != nil {
+		return nil
+	}
+	sort.Strings(categoryNames)
+
+	var diagnostics []storage.Diagnostic
+	for _, categoryName := range categoryNames {
+		if _, styled := cfg.Categories[categoryName]; styled {
+			continue
+		}
+
+		diagnostics = append(diagnostics, storage.Diagnostic{
+			File: filepath.Join(
+				cfg.GetDataFolderWithFallback(),
+				strconv.Itoa(year),
+				categoryName+".csv",
+			),
+			Line:     1,
+			Column:   1,
+			Severity: storage.SeverityWarning,
+			Message: fmt.Sprintf(
+				"category %q has no style in config, a generated color is used",
+				categoryName,
+			),
+		})
+	}
+
+	return diagnostics
+}
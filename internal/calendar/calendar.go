@@ -1,23 +1,97 @@
 package calendar
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/nsr888/lifecalendar/internal/entity"
 )
 
-func NewRenderContext() *entity.RenderContext {
+// NewRenderContext builds the render context from the configured first
+// weekday and weekend days, both counted from Monday = 0.
+func NewRenderContext(firstWeekday int, weekendDays []int) *entity.RenderContext {
+	if firstWeekday < 0 || firstWeekday > 6 {
+		firstWeekday = 0
+	}
+
 	return &entity.RenderContext{
-		FirstWeekday: 0,
-		WeekendDays:  getDefaultWeekendDays(),
+		FirstWeekday: firstWeekday,
+		WeekendDays:  newWeekendDays(weekendDays),
 		MonthNames:   getDefaultMonthNames(),
 		WeekdayNames: getDefaultWeekdayNames(),
 	}
 }
 
-func MonthCalendar(year int, month time.Month) [][]int {
-	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
-	weekdayFirst := (int(firstOfMonth.Weekday()) + 6) % 7
+// NewWorkSchedule builds a schedule from weekly working-day patterns. The
+// anchor is moved back to its Monday so rotation weeks run Monday to Sunday.
+func NewWorkSchedule(from, to, anchor entity.Date, weeks [][]int) entity.WorkSchedule {
+	schedule := entity.WorkSchedule{
+		From:   from,
+		To:     to,
+		Anchor: anchor.AddDate(0, 0, -WeekdayIndex(anchor)),
+	}
+
+	for _, days := range weeks {
+		working := make(map[int]struct{}, len(days))
+		for _, day := range days {
+			if day >= 0 && day <= 6 {
+				working[day] = struct{}{}
+			}
+		}
+		schedule.Weeks = append(schedule.Weeks, working)
+	}
+
+	return schedule
+}
+
+// YearPeriod is the calendar year, titled with the year number.
+func YearPeriod(year int) entity.Period {
+	return FiscalPeriod(year, time.January)
+}
+
+// FiscalPeriod is the twelve months starting in startMonth of the year,
+// titled like "FY2025/26". A January start is the calendar year.
+func FiscalPeriod(year int, startMonth time.Month) entity.Period {
+	start := entity.NewDate(year, startMonth, 1)
+	period := entity.Period{
+		Start: start,
+		End:   start.AddDate(1, 0, -1),
+		Title: strconv.Itoa(year),
+	}
+
+	if startMonth != time.January {
+		period.Title = fmt.Sprintf("FY%d/%02d", year, (year+1)%100)
+	}
+
+	return period
+}
+
+// CustomPeriod is an arbitrary range of days. Ranges of whole months are
+// titled like "Apr 2025 – Mar 2026", others with their dates.
+func CustomPeriod(from, to entity.Date) entity.Period {
+	layout := "2006-01-02"
+	if from.Day() == 1 && to.AddDate(0, 0, 1).Day() == 1 {
+		layout = "Jan 2006"
+	}
+
+	title := from.Format(layout) + " – " + to.Format(layout)
+	if from.Format(layout) == to.Format(layout) {
+		title = from.Format(layout)
+	}
+
+	return entity.Period{
+		Start: from,
+		End:   to,
+		Title: title,
+	}
+}
+
+// MonthCalendar returns the weeks of a month as rows of day numbers, with
+// weeks starting on firstWeekday (Monday = 0) and zeros outside the month.
+func MonthCalendar(year int, month time.Month, firstWeekday int) [][]int {
+	firstOfMonth := entity.NewDate(year, month, 1)
+	weekdayFirst := WeekdayColumn(firstOfMonth, firstWeekday)
 
 	dim := daysInMonth(year, month)
 
@@ -43,51 +117,116 @@ func MonthCalendar(year int, month time.Month) [][]int {
 	return weeks
 }
 
+// WeekdayIndex returns the weekday of the date counted from Monday = 0.
+func WeekdayIndex(date entity.Date) int {
+	return (int(date.Weekday()) + 6) % 7
+}
+
+// WeekdayColumn returns the column of the date in a week starting on
+// firstWeekday (Monday = 0).
+func WeekdayColumn(date entity.Date, firstWeekday int) int {
+	return (WeekdayIndex(date) - firstWeekday + 7) % 7
+}
+
+// RowISOWeek returns the ISO week number of the calendar row containing the
+// date. A row that does not start on Monday is numbered by the Monday in it.
+func RowISOWeek(date entity.Date, firstWeekday int) int {
+	rowStart := date.AddDate(0, 0, -WeekdayColumn(date, firstWeekday))
+	monday := rowStart.AddDate(0, 0, (8-int(rowStart.Weekday()))%7)
+	_, week := monday.ISOWeek()
+
+	return week
+}
+
+// WeekdayHeader returns two-letter weekday names in column order.
+func WeekdayHeader(ctx *entity.RenderContext) []string {
+	header := make([]string, 0, len(ctx.WeekdayNames))
+	for i := range ctx.WeekdayNames {
+		name := ctx.WeekdayNames[(ctx.FirstWeekday+i)%len(ctx.WeekdayNames)]
+		if len(name) > 2 {
+			name = name[:2]
+		}
+		header = append(header, name)
+	}
+
+	return header
+}
+
 // CountDaysInYear counts vacation and personal days in a year.
-func CountDaysInYear(cfg *entity.CategoryName, year int) (int, int) {
-	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
-	end := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.Local)
-	return CountDaysInPeriod(cfg, start, end)
+func CountDaysInYear(
+	cfg *entity.CategoryName,
+	year int,
+	ctx *entity.RenderContext,
+) (float64, float64) {
+	start := entity.NewDate(year, 1, 1)
+	end := entity.NewDate(year+1, 1, 1)
+	return CountDaysInPeriod(cfg, start, end, ctx)
 }
 
-// CountDaysInPeriod counts vacation and personal days in a period.
-func CountDaysInPeriod(cfg *entity.CategoryName, start, end time.Time) (int, int) {
-	var vacDays, persDays int
+// CountDaysInPeriod counts vacation and personal days in a period. Half days
+// count as 0.5, and a half-day holiday leaves only half a day to take off.
+func CountDaysInPeriod(
+	cfg *entity.CategoryName,
+	start, end entity.Date,
+	ctx *entity.RenderContext,
+) (float64, float64) {
+	var vacDays, persDays float64
 	vacCat := cfg.Categories["vacations"]
 	persCat := cfg.Categories["personal_days"]
 	holCat := cfg.Categories["public_holidays"]
 
-	ctx := NewRenderContext()
-
 	for cur := start; cur.Before(end); cur = cur.AddDate(0, 0, 1) {
-		weekday := (int(cur.Weekday()) + 6) % 7
-
-		// Skip weekends
-		if _, isWknd := ctx.WeekendDays[weekday]; isWknd {
+		// Skip weekends and other days off of the work schedule
+		if !ctx.IsWorkingDay(cur) {
 			continue
 		}
 
 		// Skip public holidays
+		working := 1.0
 		if holCat != nil {
-			if _, isHol := holCat.Dates[cur]; isHol {
-				continue
-			}
+			working -= holCat.DayFraction(cur)
+		}
+		if working <= 0 {
+			continue
 		}
 
 		if vacCat != nil {
-			if _, ok := vacCat.Dates[cur]; ok {
-				vacDays++
-			}
+			vacDays += min(vacCat.DayFraction(cur), working)
 		}
 		if persCat != nil {
-			if _, ok := persCat.Dates[cur]; ok {
-				persDays++
-			}
+			persDays += min(persCat.DayFraction(cur), working)
 		}
 	}
 	return vacDays, persDays
 }
 
+// WorkingDays counts the working days an entry takes off, like
+// CountDaysInPeriod: half days count as 0.5 and public holidays reduce the
+// part of a day that can be taken off. holidays may be nil.
+func WorkingDays(
+	entry entity.CategoryEntry,
+	holidays *entity.Category,
+	ctx *entity.RenderContext,
+) float64 {
+	var days float64
+
+	for cur := entry.DateStart; !cur.After(entry.DateEnd); cur = cur.AddDate(0, 0, 1) {
+		if !ctx.IsWorkingDay(cur) {
+			continue
+		}
+
+		working := 1.0
+		if holidays != nil {
+			working -= holidays.DayFraction(cur)
+		}
+		if working > 0 {
+			days += min(entry.Portion.Fraction(), working)
+		}
+	}
+
+	return days
+}
+
 func daysInMonth(year int, month time.Month) int {
 	switch month {
 	case time.January, time.March, time.May, time.July, time.August, time.October, time.December:
@@ -135,9 +274,15 @@ func getDefaultWeekdayNames() []string {
 	return []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
 }
 
-func getDefaultWeekendDays() map[int]struct{} {
-	return map[int]struct{}{
-		5: {},
-		6: {},
+// newWeekendDays converts the configured weekend days to a set, ignoring
+// values outside Monday = 0 .. Sunday = 6.
+func newWeekendDays(weekendDays []int) map[int]struct{} {
+	result := make(map[int]struct{}, len(weekendDays))
+	for _, day := range weekendDays {
+		if day >= 0 && day <= 6 {
+			result[day] = struct{}{}
+		}
 	}
+
+	return result
 }
@@ -0,0 +1,168 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+var (
+	isoWeekPattern = regexp.MustCompile(`^(\d{4})-w(\d{1,2})$`)
+	quarterPattern = regexp.MustCompile(`^(\d{4})-q([1-4])$`)
+	monthPattern   = regexp.MustCompile(`^(\d{4})-(\d{1,2})$`)
+	yearPattern    = regexp.MustCompile(`^\d{4}$`)
+	offsetPattern  = regexp.MustCompile(`^([+-])(\d+)([dw])$`)
+)
+
+// ParseDateRange parses a date expression into the first and the last day it
+// covers. A range is two expressions joined by "..", e.g.
+// "2025-07-01..2025-07-14". Single expressions are
+//
+//   - a date "2025-07-01", or "today", "tomorrow" and "yesterday"
+//   - an ISO week "2025-W32", covering Monday to Sunday
+//   - a quarter "2025-Q3", a month "2025-07" or a year "2025"
+//   - a weekday "friday" or "next friday", the first one after today, and
+//     "last friday", the last one before today
+//   - an offset "+3d", "-1d" or "+2w" from today, or from the start when it
+//     ends a range, so "2025-07-01..+13d" covers two weeks
+func ParseDateRange(expr string, today entity.Date) (entity.Date, entity.Date, error) {
+	expr = strings.ToLower(strings.Join(strings.Fields(expr), " "))
+	if expr == "" {
+		return entity.Date{}, entity.Date{}, fmt.Errorf("empty date expression")
+	}
+
+	first, last, isRange := strings.Cut(expr, "..")
+
+	start, end, err := parseDateTerm(strings.TrimSpace(first), today, today)
+	if err != nil {
+		return entity.Date{}, entity.Date{}, err
+	}
+
+	if isRange {
+		_, end, err = parseDateTerm(strings.TrimSpace(last), today, start)
+		if err != nil {
+			return entity.Date{}, entity.Date{}, err
+		}
+	}
+
+	if end.Before(start) {
+		return entity.Date{}, entity.Date{}, fmt.Errorf("%q ends before it starts", expr)
+	}
+
+	return start, end, nil
+}
+
+// parseDateTerm parses a single expression. Offsets count from base.
+func parseDateTerm(term string, today, base entity.Date) (entity.Date, entity.Date, error) {
+	switch term {
+	case "today":
+		return today, today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today.AddDate(0, 0, -1), nil
+	}
+
+	if match := isoWeekPattern.FindStringSubmatch(term); match != nil {
+		return parseISOWeek(match[1], match[2])
+	}
+
+	if start, end, ok := parseCalendarSpan(term); ok {
+		return start, end, nil
+	}
+
+	if match := offsetPattern.FindStringSubmatch(term); match != nil {
+		count, _ := strconv.Atoi(match[2])
+		if match[1] == "-" {
+			count = -count
+		}
+		if match[3] == "w" {
+			count *= 7
+		}
+		date := base.AddDate(0, 0, count)
+		return date, date, nil
+	}
+
+	if date, ok := parseWeekdayTerm(term, today); ok {
+		return date, date, nil
+	}
+
+	date, err := entity.ParseDate(term)
+	if err != nil {
+		return entity.Date{}, entity.Date{}, fmt.Errorf("unknown date expression %q", term)
+	}
+
+	return date, date, nil
+}
+
+// parseISOWeek returns Monday and Sunday of the ISO week.
+func parseISOWeek(yearText, weekText string) (entity.Date, entity.Date, error) {
+	year, _ := strconv.Atoi(yearText)
+	week, _ := strconv.Atoi(weekText)
+
+	// January 4th is always in week 1.
+	jan4 := entity.NewDate(year, time.January, 4)
+	monday := jan4.AddDate(0, 0, 7*(week-1)-WeekdayIndex(jan4))
+
+	if isoYear, isoWeek := monday.ISOWeek(); week < 1 || isoYear != year || isoWeek != week {
+		return entity.Date{}, entity.Date{}, fmt.Errorf("%d has no ISO week %d", year, week)
+	}
+
+	return monday, monday.AddDate(0, 0, 6), nil
+}
+
+// parseCalendarSpan parses a quarter, a month or a year into its first and
+// last day.
+func parseCalendarSpan(term string) (entity.Date, entity.Date, bool) {
+	var start entity.Date
+	var months int
+
+	if match := quarterPattern.FindStringSubmatch(term); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		quarter, _ := strconv.Atoi(match[2])
+		start, months = entity.NewDate(year, time.Month(3*quarter-2), 1), 3
+	} else if match = monthPattern.FindStringSubmatch(term); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		if month < 1 || month > 12 {
+			return entity.Date{}, entity.Date{}, false
+		}
+		start, months = entity.NewDate(year, time.Month(month), 1), 1
+	} else if yearPattern.MatchString(term) {
+		year, _ := strconv.Atoi(term)
+		start, months = entity.NewDate(year, time.January, 1), 12
+	} else {
+		return entity.Date{}, entity.Date{}, false
+	}
+
+	return start, start.AddDate(0, months, -1), true
+}
+
+// parseWeekdayTerm parses "friday", "next friday" and "last friday".
+func parseWeekdayTerm(term string, today entity.Date) (entity.Date, bool) {
+	direction := 1
+	if rest, found := strings.CutPrefix(term, "next "); found {
+		term = rest
+	} else if rest, found = strings.CutPrefix(term, "last "); found {
+		term, direction = rest, -1
+	}
+
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if term != name && term != name[:3] {
+			continue
+		}
+
+		date := today.AddDate(0, 0, direction)
+		for date.Weekday() != weekday {
+			date = date.AddDate(0, 0, direction)
+		}
+		return date, true
+	}
+
+	return entity.Date{}, false
+}
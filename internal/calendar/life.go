@@ -0,0 +1,71 @@
+package calendar
+
+import (
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+// WeeksPerLifeYear is the number of cells in a row of the life calendar. The
+// last week of a year of age runs up to the next birthday.
+const WeeksPerLifeYear = 52
+
+// LifeWeeks splits the years of age into weeks starting on the birthday.
+// The category of a week is the one the day index assigns to more than
+// half of its days.
+func LifeWeeks(
+	birth entity.Date,
+	years int,
+	dayIndex *entity.DayIndex,
+) [][]entity.LifeWeek {
+	rows := make([][]entity.LifeWeek, years)
+
+	for age := range years {
+		birthday := birth.AddDate(age, 0, 0)
+		nextBirthday := birth.AddDate(age+1, 0, 0)
+
+		rows[age] = make([]entity.LifeWeek, WeeksPerLifeYear)
+		for week := range WeeksPerLifeYear {
+			start := birthday.AddDate(0, 0, 7*week)
+			end := start.AddDate(0, 0, 6)
+			if week == WeeksPerLifeYear-1 {
+				end = nextBirthday.AddDate(0, 0, -1)
+			}
+
+			rows[age][week] = entity.LifeWeek{
+				Start:    start,
+				End:      end,
+				Category: weekCategory(start, end, dayIndex),
+			}
+		}
+	}
+
+	return rows
+}
+
+func weekCategory(start, end entity.Date, dayIndex *entity.DayIndex) string {
+	counts := make(map[string]int)
+	days := end.DaysSince(start) + 1
+
+	for _, segment := range dayIndex.Segments(start, end) {
+		if segment.Info.Category != "" {
+			counts[segment.Info.Category] += segment.End.DaysSince(segment.Start) + 1
+		}
+	}
+
+	for category, count := range counts {
+		if count*2 > days {
+			return category
+		}
+	}
+
+	return ""
+}
+
+// AgeAt returns the age in full years on the date.
+func AgeAt(birth, date entity.Date) int {
+	age := date.Year() - birth.Year()
+	if birth.AddDate(age, 0, 0).After(date) {
+		age--
+	}
+
+	return age
+}
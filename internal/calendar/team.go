@@ -0,0 +1,106 @@
+package calendar
+
+import (
+	"slices"
+
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+// LeaveByDay returns the vacation and personal leave booked on each working
+// day of the period. Like CountDaysInPeriod, public holidays reduce the part
+// of a day that can be taken off.
+func LeaveByDay(
+	cfg *entity.CategoryName,
+	period entity.Period,
+	ctx *entity.RenderContext,
+) map[entity.Date]float64 {
+	leave := make(map[entity.Date]float64)
+	vacCat := cfg.Categories["vacations"]
+	persCat := cfg.Categories["personal_days"]
+	holCat := cfg.Categories["public_holidays"]
+
+	for cur := period.Start; !cur.After(period.End); cur = cur.AddDate(0, 0, 1) {
+		if !ctx.IsWorkingDay(cur) {
+			continue
+		}
+
+		working := 1.0
+		if holCat != nil {
+			working -= holCat.DayFraction(cur)
+		}
+
+		var booked float64
+		if vacCat != nil {
+			booked += vacCat.DayFraction(cur)
+		}
+		if persCat != nil {
+			booked += persCat.DayFraction(cur)
+		}
+
+		if away := min(booked, working); away > 0 {
+			leave[cur] = away
+		}
+	}
+
+	return leave
+}
+
+// TeamCoverage counts the team members away on each day.
+func TeamCoverage(members []entity.TeamMember) map[entity.Date]int {
+	coverage := make(map[entity.Date]int)
+	for _, member := range members {
+		for date := range member.Absent {
+			coverage[date]++
+		}
+	}
+
+	return coverage
+}
+
+// FindTeamConflicts returns the runs of days on which more than maxAbsent
+// members are away. Days off in between, like a weekend, do not end a run.
+// A maxAbsent of 0 disables the check.
+func FindTeamConflicts(
+	members []entity.TeamMember,
+	period entity.Period,
+	maxAbsent int,
+	ctx *entity.RenderContext,
+) []entity.TeamConflict {
+	if maxAbsent <= 0 {
+		return nil
+	}
+
+	coverage := TeamCoverage(members)
+
+	var conflicts []entity.TeamConflict
+	var current *entity.TeamConflict
+
+	for cur := period.Start; !cur.After(period.End); cur = cur.AddDate(0, 0, 1) {
+		count := coverage[cur]
+		if count <= maxAbsent {
+			if ctx.IsWorkingDay(cur) && current != nil {
+				conflicts = append(conflicts, *current)
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil {
+			current = &entity.TeamConflict{Start: cur}
+		}
+		current.End = cur
+		current.MaxAbsent = max(current.MaxAbsent, count)
+
+		for _, member := range members {
+			if _, away := member.Absent[cur]; away && !slices.Contains(current.People, member.Name) {
+				current.People = append(current.People, member.Name)
+			}
+		}
+	}
+
+	if current != nil {
+		conflicts = append(conflicts, *current)
+	}
+
+	return conflicts
+}
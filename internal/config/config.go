@@ -4,15 +4,28 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/jinzhu/configor"
+	"github.com/nsr888/lifecalendar/internal/entity"
 	"golang.org/x/term"
 )
 
 const (
 	defaultConfigPath = "config.toml"
 	defaultDataFolder = "data"
+	defaultSQLiteFile = "lifecalendar.db"
+
+	StorageCSV    = "csv"
+	StorageSQLite = "sqlite"
+
+	PeriodCalendar = "calendar"
+	PeriodFiscal   = "fiscal"
+	PeriodCustom   = "custom"
+
+	FormatLife = "life"
 )
 
 type ColorStyle struct {
@@ -28,9 +41,69 @@ type CategoryConfig struct {
 	Priority int `toml:"priority"`
 }
 
+// Allowance describes the yearly vacation entitlement and its carry-over rules.
+type Allowance struct {
+	Entitlement     float64            `toml:"entitlement"`       // days per year
+	Entitlements    map[string]float64 `toml:"entitlements"`      // per-year, e.g. "2026" = 27
+	MonthlyAccrual  bool               `toml:"monthly_accrual"`   // earn 1/12 per month
+	CarryOverCap    float64            `toml:"carry_over_cap"`    // max days moved to next year
+	CarryOverExpiry string             `toml:"carry_over_expiry"` // "MM-DD", carried days expire
+}
+
+// Optimizer constrains the bridge-day vacation optimizer of the JSON plan.
+type Optimizer struct {
+	Budget       int   `toml:"budget"`         // days to place; defaults to the remaining allowance
+	AvoidMonths  []int `toml:"avoid_months"`   // 1 = January
+	MinBlockDays int   `toml:"min_block_days"` // minimum days off per block
+	MaxBlocks    int   `toml:"max_blocks"`     // 0 means unlimited
+}
+
+// Schedule is a work-week pattern. Weeks lists the working weekdays
+// (Monday = 0) of each week of a rotation that starts in the week of Anchor.
+// From and To ("YYYY-MM-DD", both optional) limit when the schedule applies.
+type Schedule struct {
+	From   string  `toml:"from"`
+	To     string  `toml:"to"`
+	Anchor string  `toml:"anchor"`
+	Weeks  [][]int `toml:"weeks"`
+}
+
+// Generator defines a rotation category repeating every cycle, e.g. on-call
+// every third week. Active days are day offsets within the cycle, 0 being
+// the anchor day; they default to the first week (or first day) of the cycle.
+// Weekly cycles without an anchor count ISO weeks from week 1 of each year.
+type Generator struct {
+	CycleWeeks int    `toml:"cycle_weeks"`
+	CycleDays  int    `toml:"cycle_days"`
+	Anchor     string `toml:"anchor"`      // first day of a cycle, "YYYY-MM-DD"
+	ActiveDays []int  `toml:"active_days"` // offsets within the cycle
+	Label      string `toml:"label"`       // legend text, defaults to the name
+}
+
+// Life configures the "life" render format, one cell per week of a life.
+type Life struct {
+	BirthDate      string `toml:"birth_date"`      // "YYYY-MM-DD"
+	LifeExpectancy int    `toml:"life_expectancy"` // years, one row each
+}
+
+// Person is a team member whose CSV data folder is shown in the team view.
+type Person struct {
+	DataFolder string `toml:"data_folder"`
+}
+
+// Period selects the window every calendar covers.
+type Period struct {
+	Type       string `toml:"type"`        // "calendar" (default), "fiscal" or "custom"
+	StartMonth int    `toml:"start_month"` // first month of a fiscal year, 1 = January
+	From       string `toml:"from"`        // first day of a custom period, "YYYY-MM-DD"
+	To         string `toml:"to"`          // last day of a custom period
+}
+
 type Config struct {
 	Years      []int  `toml:"years"`
 	DataFolder string `toml:"data_folder"`
+	Storage    string `toml:"storage"`     // "csv" (default) or "sqlite"
+	SQLitePath string `toml:"sqlite_path"` // defaults to <data_folder>/lifecalendar.db
 	JSONPlan   bool   // CLI flag for JSON output mode
 	AIReview   bool   // CLI flag for AI review mode
 	Rendering  struct {
@@ -38,7 +111,21 @@ type Config struct {
 		FirstWeekday    int    `toml:"first_weekday"`
 		WeekendDays     []int  `toml:"weekend_days"`
 		Format          string `toml:"format"`
+		WeekNumbers     bool   `toml:"week_numbers"` // ISO week gutter left of each row
 	} `toml:"rendering"`
+	Holidays struct {
+		Country string `toml:"country"` // built-in rule set, e.g. "DE"; empty disables
+	} `toml:"holidays"`
+	Allowance  Allowance            `toml:"allowance"`
+	Optimizer  Optimizer            `toml:"optimizer"`
+	Schedules  []Schedule           `toml:"schedules"`
+	Period     Period               `toml:"period"`
+	Generators map[string]Generator `toml:"generators"`
+	People     map[string]Person    `toml:"people"`
+	Life       Life                 `toml:"life"`
+	Team       struct {
+		MaxAbsent int `toml:"max_absent"` // warn when more people are away at once; 0 disables
+	} `toml:"team"`
 	Categories map[string]CategoryConfig `toml:"categories"`
 }
 
@@ -47,11 +134,15 @@ func Load(configPath string) (*Config, error) {
 	currentYear := time.Now().Year()
 	config.Years = []int{currentYear}
 	config.DataFolder = defaultDataFolder
+	config.Storage = StorageCSV
 	config.Rendering.MaxWidthInChars = getTerminalWidth()
 	config.Rendering.FirstWeekday = 0
 	config.Rendering.WeekendDays = []int{5, 6}
 	config.Rendering.Format = "compact"
+	config.Period.Type = PeriodCalendar
+	config.Life.LifeExpectancy = 80
 
+	config.Generators = make(map[string]Generator)
 	config.Categories = make(map[string]CategoryConfig)
 
 	if _, err := os.Stat(configPath); err == nil {
@@ -61,9 +152,308 @@ func Load(configPath string) (*Config, error) {
 		}
 	}
 
+	switch config.Storage {
+	case StorageCSV, StorageSQLite:
+	default:
+		return nil, fmt.Errorf(
+			"unknown storage %q: use %q or %q",
+			config.Storage,
+			StorageCSV,
+			StorageSQLite,
+		)
+	}
+
+	if err := config.Allowance.validate(); err != nil {
+		return nil, fmt.Errorf("invalid allowance: %w", err)
+	}
+
+	if err := config.Optimizer.validate(); err != nil {
+		return nil, fmt.Errorf("invalid optimizer: %w", err)
+	}
+
+	if err := config.Period.validate(); err != nil {
+		return nil, fmt.Errorf("invalid period: %w", err)
+	}
+
+	for i, schedule := range config.Schedules {
+		if _, _, _, err := schedule.Dates(); err != nil {
+			return nil, fmt.Errorf("invalid schedule %d: %w", i+1, err)
+		}
+	}
+
+	if err := config.Life.validate(config.Rendering.Format); err != nil {
+		return nil, fmt.Errorf("invalid life: %w", err)
+	}
+
+	for name, person := range config.People {
+		if person.DataFolder == "" {
+			return nil, fmt.Errorf("person %q has no data_folder", name)
+		}
+	}
+	if config.Team.MaxAbsent < 0 {
+		return nil, fmt.Errorf("team max_absent must not be negative")
+	}
+
+	for name, generator := range defaultGenerators() {
+		if _, exists := config.Generators[name]; !exists {
+			config.Generators[name] = generator
+		}
+	}
+
+	for name, generator := range config.Generators {
+		if _, err := generator.Cycle(); err != nil {
+			return nil, fmt.Errorf("invalid generator %q: %w", name, err)
+		}
+	}
+
 	return config, nil
 }
 
+func (o Optimizer) validate() error {
+	for _, month := range o.AvoidMonths {
+		if month < 1 || month > 12 {
+			return fmt.Errorf("avoid_months value %d is not a month", month)
+		}
+	}
+
+	if o.Budget < 0 || o.MinBlockDays < 0 || o.MaxBlocks < 0 {
+		return fmt.Errorf("budget, min_block_days and max_blocks must not be negative")
+	}
+
+	return nil
+}
+
+func (p Period) validate() error {
+	switch p.Type {
+	case PeriodCalendar:
+		return nil
+	case PeriodFiscal:
+		if p.StartMonth < 1 || p.StartMonth > 12 {
+			return fmt.Errorf("start_month %d is not a month", p.StartMonth)
+		}
+		return nil
+	case PeriodCustom:
+		_, _, err := p.Range()
+		return err
+	default:
+		return fmt.Errorf(
+			"unknown type %q: use %q, %q or %q",
+			p.Type,
+			PeriodCalendar,
+			PeriodFiscal,
+			PeriodCustom,
+		)
+	}
+}
+
+// Range parses the from and to dates of a custom period.
+func (p Period) Range() (entity.Date, entity.Date, error) {
+	from, err := entity.ParseDate(p.From)
+	if err != nil {
+		return entity.Date{}, entity.Date{}, fmt.Errorf("from %q is not YYYY-MM-DD", p.From)
+	}
+
+	to, err := entity.ParseDate(p.To)
+	if err != nil {
+		return entity.Date{}, entity.Date{}, fmt.Errorf("to %q is not YYYY-MM-DD", p.To)
+	}
+
+	if to.Before(from) {
+		return entity.Date{}, entity.Date{}, fmt.Errorf("to %s is before from %s", p.To, p.From)
+	}
+
+	return from, to, nil
+}
+
+func (l Life) validate(format string) error {
+	if l.BirthDate == "" && format != FormatLife {
+		return nil
+	}
+
+	if _, err := l.Birth(); err != nil {
+		return err
+	}
+	if l.LifeExpectancy < 1 {
+		return fmt.Errorf("life_expectancy must be at least one year")
+	}
+
+	return nil
+}
+
+// Birth parses the birth date.
+func (l Life) Birth() (entity.Date, error) {
+	birth, err := entity.ParseDate(l.BirthDate)
+	if err != nil {
+		return entity.Date{}, fmt.Errorf("birth_date %q is not YYYY-MM-DD", l.BirthDate)
+	}
+
+	return birth, nil
+}
+
+// defaultGenerators keeps the odd_week and even_week categories of ISO week
+// parity available without configuration.
+func defaultGenerators() map[string]Generator {
+	return map[string]Generator{
+		"odd_week":  {CycleWeeks: 2, ActiveDays: []int{0, 1, 2, 3, 4, 5, 6}},
+		"even_week": {CycleWeeks: 2, ActiveDays: []int{7, 8, 9, 10, 11, 12, 13}},
+	}
+}
+
+// Cycle returns the cycle length in days and validates the generator.
+func (g Generator) Cycle() (int, error) {
+	if (g.CycleWeeks > 0) == (g.CycleDays > 0) {
+		return 0, fmt.Errorf("set exactly one of cycle_weeks and cycle_days")
+	}
+	if g.CycleWeeks < 0 || g.CycleDays < 0 {
+		return 0, fmt.Errorf("cycle length must not be negative")
+	}
+
+	length := g.CycleDays + 7*g.CycleWeeks
+
+	if _, err := g.AnchorDate(); err != nil {
+		return 0, err
+	}
+	if g.CycleDays > 0 && g.Anchor == "" {
+		return 0, fmt.Errorf("cycle_days needs an anchor date")
+	}
+
+	for _, day := range g.ActiveDays {
+		if day < 0 || day >= length {
+			return 0, fmt.Errorf("active day %d is outside the %d-day cycle", day, length)
+		}
+	}
+
+	return length, nil
+}
+
+// AnchorDate parses the anchor, returning the zero time when it is not set.
+func (g Generator) AnchorDate() (entity.Date, error) {
+	if g.Anchor == "" {
+		return entity.Date{}, nil
+	}
+
+	anchor, err := entity.ParseDate(g.Anchor)
+	if err != nil {
+		return entity.Date{}, fmt.Errorf("anchor %q is not YYYY-MM-DD", g.Anchor)
+	}
+
+	return anchor, nil
+}
+
+// SetRolling replaces the period with a custom one covering the given number
+// of months, starting with the month of today.
+func (c *Config) SetRolling(months int, today entity.Date) error {
+	if months < 1 {
+		return fmt.Errorf("rolling window must be at least one month, got %d", months)
+	}
+
+	from := entity.NewDate(today.Year(), today.Month(), 1)
+	c.SetCustomPeriod(from, from.AddDate(0, months, -1))
+
+	return nil
+}
+
+// SetCustomPeriod replaces the period with a custom one from from to to.
+func (c *Config) SetCustomPeriod(from, to entity.Date) {
+	c.Period = Period{
+		Type: PeriodCustom,
+		From: from.Format("2006-01-02"),
+		To:   to.Format("2006-01-02"),
+	}
+}
+
+// Dates parses the schedule's dates and validates its weeks. Missing dates
+// are returned as zero times.
+func (sc Schedule) Dates() (from, to, anchor entity.Date, err error) {
+	parse := func(name, value string) (entity.Date, error) {
+		if value == "" {
+			return entity.Date{}, nil
+		}
+		date, parseErr := entity.ParseDate(value)
+		if parseErr != nil {
+			return entity.Date{}, fmt.Errorf("%s %q is not YYYY-MM-DD", name, value)
+		}
+		return date, nil
+	}
+
+	if from, err = parse("from", sc.From); err != nil {
+		return
+	}
+	if to, err = parse("to", sc.To); err != nil {
+		return
+	}
+	if anchor, err = parse("anchor", sc.Anchor); err != nil {
+		return
+	}
+
+	switch {
+	case len(sc.Weeks) == 0:
+		err = fmt.Errorf("weeks must list at least one week")
+	case len(sc.Weeks) > 1 && anchor.IsZero():
+		err = fmt.Errorf("a rotation of %d weeks needs an anchor date", len(sc.Weeks))
+	case !from.IsZero() && !to.IsZero() && to.Before(from):
+		err = fmt.Errorf("to %s is before from %s", sc.To, sc.From)
+	}
+	if err != nil {
+		return
+	}
+
+	for _, week := range sc.Weeks {
+		for _, day := range week {
+			if day < 0 || day > 6 {
+				err = fmt.Errorf("weekday %d is outside Monday = 0 .. Sunday = 6", day)
+				return
+			}
+		}
+	}
+
+	return
+}
+
+func (a Allowance) validate() error {
+	for year := range a.Entitlements {
+		if _, err := strconv.Atoi(year); err != nil {
+			return fmt.Errorf("entitlements key %q is not a year", year)
+		}
+	}
+
+	if a.CarryOverExpiry != "" {
+		if _, err := time.Parse("01-02", a.CarryOverExpiry); err != nil {
+			return fmt.Errorf("carry_over_expiry %q is not MM-DD", a.CarryOverExpiry)
+		}
+	}
+
+	return nil
+}
+
+// Enabled reports whether an entitlement is configured at all.
+func (a Allowance) Enabled() bool {
+	return a.Entitlement > 0 || len(a.Entitlements) > 0
+}
+
+// EntitlementFor returns the entitlement of the year, honoring per-year overrides.
+func (a Allowance) EntitlementFor(year int) float64 {
+	if days, exists := a.Entitlements[strconv.Itoa(year)]; exists {
+		return days
+	}
+	return a.Entitlement
+}
+
+// CarryOverExpiryDate returns the last day carried-over days can be taken in
+// the year, or false when carried days never expire.
+func (a Allowance) CarryOverExpiryDate(year int) (entity.Date, bool) {
+	if a.CarryOverExpiry == "" {
+		return entity.Date{}, false
+	}
+
+	date, err := time.Parse("01-02", a.CarryOverExpiry)
+	if err != nil {
+		return entity.Date{}, false
+	}
+
+	return entity.NewDate(year, date.Month(), date.Day()), true
+}
+
 func getTerminalWidth() int {
 	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
 		if width >= 20 {
@@ -137,6 +527,15 @@ func (c *Config) GetCategoryConfig(categoryName string) CategoryConfig {
 	}
 }
 
+// CategoryPriority returns the configured priority of the category, or
+// entity.NoPriority when it has no style.
+func (c *Config) CategoryPriority(categoryName string) int {
+	if config, exists := c.Categories[categoryName]; exists {
+		return config.Priority
+	}
+	return entity.NoPriority
+}
+
 func (c *Config) GetDataFolder() string {
 	if c.DataFolder != "" {
 		return c.DataFolder
@@ -160,6 +559,13 @@ func (c *Config) GetDataFolderWithFallback() string {
 	return primaryFolder
 }
 
+func (c *Config) GetSQLitePath() string {
+	if c.SQLitePath != "" {
+		return c.SQLitePath
+	}
+	return filepath.Join(c.GetDataFolderWithFallback(), defaultSQLiteFile)
+}
+
 func LoadDefault() (*Config, error) {
 	return Load(defaultConfigPath)
 }
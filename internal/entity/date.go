@@ -0,0 +1,111 @@
+package entity
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the format of dates in data files and config.
+const DateLayout = "2006-01-02"
+
+// Date is a civil calendar day without time of day or time zone, so day
+// arithmetic is not shifted by DST transitions or the machine's zone.
+// Dates are comparable and used as map keys; the zero Date is unset.
+type Date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// NewDate returns the date, normalizing out-of-range values the way
+// time.Date does, e.g. day 0 is the last day of the previous month.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the day of t in t's own location.
+func DateOf(t time.Time) Date {
+	return Date{year: t.Year(), month: t.Month(), day: t.Day()}
+}
+
+// Today returns the current day in the local time zone.
+func Today() Date {
+	return DateOf(time.Now())
+}
+
+// ParseDate parses a "YYYY-MM-DD" date.
+func ParseDate(value string) (Date, error) {
+	return ParseDateFormat(DateLayout, value)
+}
+
+// ParseDateFormat parses a date in the given time.Parse layout. Any time of
+// day in the layout is dropped.
+func ParseDateFormat(layout, value string) (Date, error) {
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("failed to parse date %q: %w", value, err)
+	}
+
+	return DateOf(t), nil
+}
+
+func (d Date) Year() int         { return d.year }
+func (d Date) Month() time.Month { return d.month }
+func (d Date) Day() int          { return d.day }
+
+// IsZero reports whether the date is unset.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// In returns midnight of the date in the location, for edges that need a
+// time.Time.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.year, d.month, d.day, 0, 0, 0, 0, loc)
+}
+
+func (d Date) utc() time.Time {
+	return d.In(time.UTC)
+}
+
+// AddDate adds years, months and days like time.Time.AddDate.
+func (d Date) AddDate(years, months, days int) Date {
+	return NewDate(d.year+years, d.month+time.Month(months), d.day+days)
+}
+
+// DaysSince counts the days from start to the date, negative before start.
+func (d Date) DaysSince(start Date) int {
+	return int(d.utc().Sub(start.utc()) / (24 * time.Hour))
+}
+
+// Compare returns -1, 0 or +1 when the date is before, equal to or after other.
+func (d Date) Compare(other Date) int {
+	return cmp.Compare(d.key(), other.key())
+}
+
+// key orders dates as integers; months and days fit in 4 and 5 bits.
+func (d Date) key() int {
+	return d.year<<9 | int(d.month)<<5 | d.day
+}
+
+func (d Date) Before(other Date) bool { return d.Compare(other) < 0 }
+func (d Date) After(other Date) bool  { return d.Compare(other) > 0 }
+func (d Date) Equal(other Date) bool  { return d == other }
+
+func (d Date) Weekday() time.Weekday {
+	return d.utc().Weekday()
+}
+
+func (d Date) ISOWeek() (year, week int) {
+	return d.utc().ISOWeek()
+}
+
+// Format formats the date with a time.Format layout.
+func (d Date) Format(layout string) string {
+	return d.utc().Format(layout)
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
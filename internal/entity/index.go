@@ -0,0 +1,216 @@
+package entity
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// NoPriority is the priority of categories without a style; they never win a day.
+const NoPriority = 999
+
+// Interval is a run of consecutive days covered by the same portion.
+type Interval struct {
+	Start   Date
+	End     Date // inclusive
+	Portion DayPortion
+}
+
+// Days returns the number of days in the interval.
+func (i Interval) Days() int {
+	return i.End.DaysSince(i.Start) + 1
+}
+
+// DaySegment is a run of consecutive days won by the same category.
+type DaySegment struct {
+	Start Date
+	End   Date // inclusive
+	Info  DayInfo
+}
+
+// DayIndex holds the categories of a load as sorted intervals and the
+// winning category of every day as sorted segments. It is built once and
+// answers lookups by binary search instead of probing per-day maps.
+type DayIndex struct {
+	intervals map[string][]Interval
+	segments  []DaySegment
+}
+
+// NewDayIndex builds the index of the data within [start, end]. The
+// priority function is called once per category; lower wins, ties go to
+// the alphabetically first name and NoPriority never wins.
+func NewDayIndex(
+	data *CategoryName,
+	start, end Date,
+	priority func(category string) int,
+) *DayIndex {
+	index := &DayIndex{intervals: make(map[string][]Interval)}
+	if data == nil {
+		return index
+	}
+
+	priorities := make(map[string]int, len(data.Categories))
+	for name, category := range data.Categories {
+		index.intervals[name] = category.Intervals(start, end)
+		priorities[name] = priority(name)
+	}
+
+	index.segments = sweep(index.intervals, priorities)
+
+	return index
+}
+
+// boundary is a point where an interval of the category with the given
+// rank starts, or where one has ended the day before (interval is -1).
+type boundary struct {
+	date     Date
+	rank     int
+	interval int
+}
+
+// sweep walks the interval boundaries in date order and records the winner
+// between each pair of consecutive boundaries.
+func sweep(intervals map[string][]Interval, priorities map[string]int) []DaySegment {
+	// Ranking the names by priority, then alphabetically, makes the winner
+	// the active category with the lowest rank.
+	var names []string
+	for name := range intervals {
+		if priorities[name] < NoPriority {
+			names = append(names, name)
+		}
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if priorities[a] != priorities[b] {
+			return priorities[a] - priorities[b]
+		}
+		return strings.Compare(a, b)
+	})
+
+	var boundaries []boundary
+	for rank, name := range names {
+		for i, interval := range intervals[name] {
+			boundaries = append(boundaries,
+				boundary{date: interval.Start, rank: rank, interval: i},
+				boundary{date: interval.End.AddDate(0, 0, 1), rank: rank, interval: -1},
+			)
+		}
+	}
+
+	// Ends sort before starts on the same date, so back-to-back intervals
+	// of one category do not cancel each other out.
+	slices.SortFunc(boundaries, func(a, b boundary) int {
+		if c := a.date.Compare(b.date); c != 0 {
+			return c
+		}
+		return min(a.interval, 0) - min(b.interval, 0)
+	})
+
+	// active holds the current interval of every rank, -1 when none.
+	active := slices.Repeat([]int{-1}, len(names))
+	var segments []DaySegment
+
+	for i := 0; i < len(boundaries); {
+		date := boundaries[i].date
+		for ; i < len(boundaries) && boundaries[i].date == date; i++ {
+			active[boundaries[i].rank] = boundaries[i].interval
+		}
+
+		winner := slices.IndexFunc(active, func(interval int) bool { return interval >= 0 })
+		if winner < 0 || i == len(boundaries) {
+			continue
+		}
+
+		segment := DaySegment{
+			Start: date,
+			End:   boundaries[i].date.AddDate(0, 0, -1),
+			Info: DayInfo{
+				Category: names[winner],
+				Priority: priorities[names[winner]],
+				Portion:  intervals[names[winner]][active[winner]].Portion,
+			},
+		}
+
+		if n := len(segments); n > 0 && segments[n-1].Info == segment.Info &&
+			segments[n-1].End.AddDate(0, 0, 1) == segment.Start {
+			segments[n-1].End = segment.End
+			continue
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
+
+// Add merges the segments and intervals of another index covering days
+// this one does not cover, e.g. the next period.
+func (ix *DayIndex) Add(other *DayIndex) {
+	if ix.intervals == nil {
+		ix.intervals = make(map[string][]Interval)
+	}
+
+	for name, intervals := range other.intervals {
+		merged := append(ix.intervals[name], intervals...)
+		sort.Slice(merged, func(i, j int) bool { return merged[i].Start.Before(merged[j].Start) })
+		ix.intervals[name] = merged
+	}
+
+	ix.segments = append(ix.segments, other.segments...)
+	sort.Slice(ix.segments, func(i, j int) bool {
+		return ix.segments[i].Start.Before(ix.segments[j].Start)
+	})
+}
+
+// Lookup returns the winning category of the date.
+func (ix *DayIndex) Lookup(date Date) (DayInfo, bool) {
+	i := sort.Search(len(ix.segments), func(i int) bool {
+		return !ix.segments[i].End.Before(date)
+	})
+	if i < len(ix.segments) && !ix.segments[i].Start.After(date) {
+		return ix.segments[i].Info, true
+	}
+
+	return DayInfo{}, false
+}
+
+// Segments returns the winning segments overlapping [start, end], clipped to it.
+func (ix *DayIndex) Segments(start, end Date) []DaySegment {
+	i := sort.Search(len(ix.segments), func(i int) bool {
+		return !ix.segments[i].End.Before(start)
+	})
+
+	var result []DaySegment
+	for ; i < len(ix.segments) && !ix.segments[i].Start.After(end); i++ {
+		segment := ix.segments[i]
+		if segment.Start.Before(start) {
+			segment.Start = start
+		}
+		if segment.End.After(end) {
+			segment.End = end
+		}
+		result = append(result, segment)
+	}
+
+	return result
+}
+
+// CoveredDays counts the days of [start, end] the category covers.
+func (ix *DayIndex) CoveredDays(category string, start, end Date) int {
+	intervals := ix.intervals[category]
+	i := sort.Search(len(intervals), func(i int) bool {
+		return !intervals[i].End.Before(start)
+	})
+
+	days := 0
+	for ; i < len(intervals) && !intervals[i].Start.After(end); i++ {
+		from, to := intervals[i].Start, intervals[i].End
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		days += to.DaysSince(from) + 1
+	}
+
+	return days
+}
@@ -0,0 +1,163 @@
+package entity
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+const benchCategories = 30
+
+// benchData builds categories with a few entries of one to fourteen days in
+// every year, some of them half days.
+func benchData(startYear, years int) *CategoryName {
+	rng := rand.New(rand.NewSource(1))
+	data := &CategoryName{Categories: make(map[string]*Category)}
+
+	for c := range benchCategories {
+		category := &Category{}
+		for year := startYear; year < startYear+years; year++ {
+			for range 4 {
+				start := NewDate(year, time.January, 1+rng.Intn(365))
+				portion := PortionFull
+				if rng.Intn(4) == 0 {
+					portion = PortionMorning
+				}
+				entry := CategoryEntry{
+					DateStart: start,
+					DateEnd:   start.AddDate(0, 0, rng.Intn(14)),
+					Portion:   portion,
+				}
+				category.Entries = append(category.Entries, entry)
+				for cur := entry.DateStart; !cur.After(entry.DateEnd); cur = cur.AddDate(0, 0, 1) {
+					category.AddDay(cur, portion)
+				}
+			}
+		}
+		data.Categories[fmt.Sprintf("category%02d", c)] = category
+	}
+
+	return data
+}
+
+func benchPriority(category string) int {
+	var n int
+	fmt.Sscanf(category, "category%d", &n)
+	return n % 10
+}
+
+// perDayStyles is the lookup the index replaces: every day of the period
+// probes the date map of every category.
+func perDayStyles(data *CategoryName, start, end Date) map[Date]DayInfo {
+	result := make(map[Date]DayInfo)
+
+	for cur := start; !cur.After(end); cur = cur.AddDate(0, 0, 1) {
+		winner := DayInfo{Priority: NoPriority}
+		for name, category := range data.Categories {
+			portion, exists := category.Portion(cur)
+			if !exists {
+				continue
+			}
+			priority := benchPriority(name)
+			if priority < winner.Priority || priority == winner.Priority && name < winner.Category {
+				winner = DayInfo{Category: name, Priority: priority, Portion: portion}
+			}
+		}
+		if winner.Category != "" {
+			result[cur] = winner
+		}
+	}
+
+	return result
+}
+
+func perDayStats(data *CategoryName, styles map[Date]DayInfo, start, end Date) map[string]float64 {
+	stats := make(map[string]float64)
+	for cur := start; !cur.After(end); cur = cur.AddDate(0, 0, 1) {
+		if info, exists := styles[cur]; exists {
+			stats[info.Category] += data.Categories[info.Category].DayFraction(cur)
+		}
+	}
+
+	return stats
+}
+
+func indexStats(index *DayIndex, start, end Date) map[string]float64 {
+	stats := make(map[string]float64)
+	for _, segment := range index.Segments(start, end) {
+		days := float64(segment.End.DaysSince(segment.Start) + 1)
+		stats[segment.Info.Category] += days * segment.Info.Portion.Fraction()
+	}
+
+	return stats
+}
+
+var benchSpans = []struct {
+	name  string
+	years int
+}{
+	{"years=1", 1},
+	{"years=40", 40},
+}
+
+// BenchmarkPerDayMaps styles and counts every day by probing the category maps.
+func BenchmarkPerDayMaps(b *testing.B) {
+	for _, span := range benchSpans {
+		data := benchData(1990, span.years)
+		start, end := NewDate(1990, time.January, 1), NewDate(1990+span.years-1, time.December, 31)
+
+		b.Run(span.name, func(b *testing.B) {
+			for range b.N {
+				styles := perDayStyles(data, start, end)
+				perDayStats(data, styles, start, end)
+				for cur := start; !cur.After(end); cur = cur.AddDate(0, 0, 1) {
+					_ = styles[cur]
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDayIndex does the same work with NewDayIndex, Segments and Lookup.
+func BenchmarkDayIndex(b *testing.B) {
+	for _, span := range benchSpans {
+		data := benchData(1990, span.years)
+		start, end := NewDate(1990, time.January, 1), NewDate(1990+span.years-1, time.December, 31)
+
+		b.Run(span.name, func(b *testing.B) {
+			for range b.N {
+				index := NewDayIndex(data, start, end, benchPriority)
+				indexStats(index, start, end)
+				for cur := start; !cur.After(end); cur = cur.AddDate(0, 0, 1) {
+					index.Lookup(cur)
+				}
+			}
+		})
+	}
+}
+
+// TestDayIndexMatchesPerDayMaps keeps the benchmarks comparing equal work.
+func TestDayIndexMatchesPerDayMaps(t *testing.T) {
+	data := benchData(2020, 3)
+	start, end := NewDate(2020, time.January, 1), NewDate(2022, time.December, 31)
+
+	styles := perDayStyles(data, start, end)
+	index := NewDayIndex(data, start, end, benchPriority)
+
+	for cur := start; !cur.After(end); cur = cur.AddDate(0, 0, 1) {
+		want, wantOK := styles[cur]
+		got, gotOK := index.Lookup(cur)
+		if got != want || gotOK != wantOK {
+			t.Fatalf("Lookup(%s) = %+v, %v, want %+v, %v", cur, got, gotOK, want, wantOK)
+		}
+	}
+
+	want := perDayStats(data, styles, start, end)
+	got := indexStats(index, start, end)
+	for category, days := range want {
+		if got[category] != days {
+			t.Errorf("stats[%s] = %v, want %v", category, got[category], days)
+		}
+	}
+}
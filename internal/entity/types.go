@@ -1,27 +1,245 @@
 package entity
 
-import "time"
-
-type Date = time.Time
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
 
 type CategoryType string
 
 const (
 	CategoryPlans    CategoryType = "plans"
 	CategoryWeekends CategoryType = "weekends"
+
+	CategoryNonWorkingDays CategoryType = "non_working_days"
+	CategoryWeekNumber     CategoryType = "week_number" // style of the week-number gutter
+)
+
+// DayPortion is the part of each day an entry covers.
+type DayPortion string
+
+const (
+	PortionFull      DayPortion = ""
+	PortionMorning   DayPortion = "am"
+	PortionAfternoon DayPortion = "pm"
+	PortionHalf      DayPortion = "0.5"
 )
 
+// ParseDayPortion parses the portion column: empty, "am", "pm" or "0.5".
+func ParseDayPortion(value string) (DayPortion, error) {
+	switch portion := DayPortion(strings.ToLower(strings.TrimSpace(value))); portion {
+	case PortionFull, PortionMorning, PortionAfternoon, PortionHalf:
+		return portion, nil
+	case "1", "full":
+		return PortionFull, nil
+	default:
+		return PortionFull, fmt.Errorf("invalid portion %q: use am, pm or 0.5", value)
+	}
+}
+
+// Fraction returns the share of a day the portion covers.
+func (p DayPortion) Fraction() float64 {
+	if p == PortionFull {
+		return 1
+	}
+	return 0.5
+}
+
 type CategoryEntry struct {
-	DateStart time.Time
-	DateEnd   time.Time
+	DateStart Date
+	DateEnd   Date
 	Label     string
+	Portion   DayPortion
+}
+
+// Overlaps reports whether the entry shares at least one day with [start, end].
+func (e CategoryEntry) Overlaps(start, end Date) bool {
+	return !e.DateStart.After(end) && !e.DateEnd.Before(start)
+}
+
+// Conflicts reports whether the entries book a day twice. Entries sharing
+// days do not conflict when one takes the morning and the other the
+// afternoon, as the portion applies to every day of an entry.
+func (e CategoryEntry) Conflicts(other CategoryEntry) bool {
+	return e.Overlaps(other.DateStart, other.DateEnd) && !isComplement(e.Portion, other.Portion)
+}
+
+// SplitByYear returns one entry per calendar year the entry touches.
+func (e CategoryEntry) SplitByYear() []CategoryEntry {
+	var entries []CategoryEntry
+
+	start := e.DateStart
+	for !start.After(e.DateEnd) {
+		part := e
+		part.DateStart = start
+		if yearEnd := NewDate(start.Year(), time.December, 31); yearEnd.Before(e.DateEnd) {
+			part.DateEnd = yearEnd
+		}
+		entries = append(entries, part)
+
+		start = NewDate(start.Year()+1, time.January, 1)
+	}
+
+	return entries
 }
 
 type Category struct {
-	Type    CategoryType
-	Desc    string
-	Dates   map[time.Time]struct{} // For backward compatibility
-	Entries []CategoryEntry        // New unified format
+	Type     CategoryType
+	Desc     string
+	Dates    map[Date]struct{}   // For backward compatibility
+	Portions map[Date]DayPortion // Partial days only, other dates are full days
+	Entries  []CategoryEntry     // New unified format
+}
+
+// AddDay marks the date as covered by the portion. A day covered by a
+// morning and an afternoon entry, or by any full-day entry, is a full day.
+func (c *Category) AddDay(date Date, portion DayPortion) {
+	if c.Dates == nil {
+		c.Dates = make(map[Date]struct{})
+	}
+
+	existing, partial := c.Portions[date]
+	if _, exists := c.Dates[date]; exists {
+		if partial && (portion == PortionFull || isComplement(existing, portion)) {
+			delete(c.Portions, date)
+		}
+		return
+	}
+
+	c.Dates[date] = struct{}{}
+	if portion != PortionFull {
+		if c.Portions == nil {
+			c.Portions = make(map[Date]DayPortion)
+		}
+		c.Portions[date] = portion
+	}
+}
+
+// Portion returns the part of the date the category covers and whether the
+// date is covered at all.
+func (c *Category) Portion(date Date) (DayPortion, bool) {
+	if _, exists := c.Dates[date]; !exists {
+		return PortionFull, false
+	}
+	return c.Portions[date], true
+}
+
+// DayFraction returns the share of the date the category covers, from 0 to 1.
+func (c *Category) DayFraction(date Date) float64 {
+	portion, exists := c.Portion(date)
+	if !exists {
+		return 0
+	}
+	return portion.Fraction()
+}
+
+// Intervals returns the days the entries of the category cover within
+// [start, end] as sorted runs of consecutive days with the same portion. A
+// day covered by a full entry, or by a morning and an afternoon entry, is a
+// full day, as in AddDay. Generated categories without entries, such as
+// weekends, are indexed from their days.
+func (c *Category) Intervals(start, end Date) []Interval {
+	if len(c.Entries) == 0 {
+		return c.dateIntervals(start, end)
+	}
+
+	type boundary struct {
+		date    Date
+		portion DayPortion
+		delta   int
+	}
+
+	boundaries := make([]boundary, 0, 2*len(c.Entries))
+	for _, entry := range c.Entries {
+		from, to := entry.DateStart, entry.DateEnd
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		if from.After(to) {
+			continue
+		}
+		boundaries = append(boundaries,
+			boundary{date: from, portion: entry.Portion, delta: 1},
+			boundary{date: to.AddDate(0, 0, 1), portion: entry.Portion, delta: -1},
+		)
+	}
+	slices.SortFunc(boundaries, func(a, b boundary) int { return a.date.Compare(b.date) })
+
+	// active counts the entries of every portion covering the current day.
+	active := make(map[DayPortion]int)
+	var intervals []Interval
+
+	for i := 0; i < len(boundaries); {
+		date := boundaries[i].date
+		for ; i < len(boundaries) && boundaries[i].date == date; i++ {
+			active[boundaries[i].portion] += boundaries[i].delta
+		}
+
+		portion, covered := activePortion(active)
+		if !covered || i == len(boundaries) {
+			continue
+		}
+
+		last := boundaries[i].date.AddDate(0, 0, -1)
+		if n := len(intervals); n > 0 && intervals[n-1].Portion == portion &&
+			intervals[n-1].End.AddDate(0, 0, 1) == date {
+			intervals[n-1].End = last
+			continue
+		}
+		intervals = append(intervals, Interval{Start: date, End: last, Portion: portion})
+	}
+
+	return intervals
+}
+
+// dateIntervals returns the runs of consecutive days in Dates within [start, end].
+func (c *Category) dateIntervals(start, end Date) []Interval {
+	dates := make([]Date, 0, len(c.Dates))
+	for date := range c.Dates {
+		if !date.Before(start) && !date.After(end) {
+			dates = append(dates, date)
+		}
+	}
+	slices.SortFunc(dates, Date.Compare)
+
+	var intervals []Interval
+	for _, date := range dates {
+		portion := c.Portions[date]
+		if n := len(intervals); n > 0 && intervals[n-1].Portion == portion &&
+			intervals[n-1].End.AddDate(0, 0, 1) == date {
+			intervals[n-1].End = date
+			continue
+		}
+		intervals = append(intervals, Interval{Start: date, End: date, Portion: portion})
+	}
+
+	return intervals
+}
+
+// activePortion returns the portion of a day covered by the counted entries.
+func activePortion(active map[DayPortion]int) (DayPortion, bool) {
+	if active[PortionFull] > 0 || active[PortionMorning] > 0 && active[PortionAfternoon] > 0 {
+		return PortionFull, true
+	}
+
+	for _, portion := range []DayPortion{PortionMorning, PortionAfternoon, PortionHalf} {
+		if active[portion] > 0 {
+			return portion, true
+		}
+	}
+
+	return PortionFull, false
+}
+
+func isComplement(a, b DayPortion) bool {
+	return a == PortionMorning && b == PortionAfternoon ||
+		a == PortionAfternoon && b == PortionMorning
 }
 
 type CategoryName struct {
@@ -29,18 +247,47 @@ type CategoryName struct {
 	Categories map[string]*Category
 }
 
+// Clone returns a deep copy, so the copy can be changed without touching c.
+func (c *CategoryName) Clone() *CategoryName {
+	categories := make(map[string]*Category, len(c.Categories))
+	for name, category := range c.Categories {
+		categories[name] = &Category{
+			Type:     category.Type,
+			Desc:     category.Desc,
+			Dates:    maps.Clone(category.Dates),
+			Portions: maps.Clone(category.Portions),
+			Entries:  slices.Clone(category.Entries),
+		}
+	}
+
+	return &CategoryName{BaseYear: c.BaseYear, Categories: categories}
+}
+
 type DayInfo struct {
 	Category string
 	Priority int
+	Portion  DayPortion // Portion of the winning category, full for most days
 }
 
 type VacationPlanJSON struct {
-	DateStart    string `json:"date_start"`
-	DateEnd      string `json:"date_end"`
-	Label        string `json:"label"`
-	WeekendCount int    `json:"weekend_count"`
-	HolidayCount int    `json:"holiday_count"`
-	TotalDays    int    `json:"total_days"`
+	DateStart    string  `json:"date_start"`
+	DateEnd      string  `json:"date_end"`
+	Label        string  `json:"label"`
+	Portion      string  `json:"portion,omitempty"`
+	WeekendCount int     `json:"weekend_count"`
+	HolidayCount int     `json:"holiday_count"`
+	TotalDays    float64 `json:"total_days"`
+}
+
+// ListedEntry is an entry found by the list command.
+type ListedEntry struct {
+	Category    string  `json:"category"`
+	DateStart   string  `json:"date_start"`
+	DateEnd     string  `json:"date_end"`
+	Label       string  `json:"label"`
+	Portion     string  `json:"portion,omitempty"`
+	TotalDays   float64 `json:"total_days"`
+	WorkingDays float64 `json:"working_days"`
 }
 
 type PotentialVacation struct {
@@ -52,15 +299,164 @@ type PotentialVacation struct {
 	Description  string `json:"description"`
 }
 
+// VacationBalance is the state of the vacation allowance of a year in days.
+type VacationBalance struct {
+	Entitlement float64 `json:"entitlement"`
+	CarriedOver float64 `json:"carried_over"`
+	Expired     float64 `json:"expired"`
+	Used        float64 `json:"used"`
+	Planned     float64 `json:"planned"`
+	Remaining   float64 `json:"remaining"`
+}
+
+// OptimizedBlock is one suggested vacation block of the optimizer.
+type OptimizedBlock struct {
+	DateStart    string  `json:"date_start"`
+	DateEnd      string  `json:"date_end"`
+	VacationDays int     `json:"vacation_days"`
+	TotalDays    int     `json:"total_days"`
+	Efficiency   float64 `json:"efficiency"` // days off per vacation day
+}
+
+// VacationOptimization is the best placement of a vacation day budget.
+type VacationOptimization struct {
+	Budget       int              `json:"budget"`
+	VacationDays int              `json:"vacation_days"`
+	TotalDays    int              `json:"total_days"`
+	Efficiency   float64          `json:"efficiency"`
+	Blocks       []OptimizedBlock `json:"blocks"`
+}
+
 type EnhancedJSONPlanResponse struct {
-	ExistingVacations  []VacationPlanJSON  `json:"existing_vacations"`
-	PotentialVacations []PotentialVacation `json:"potential_vacations"`
-	Year               int                 `json:"year"`
+	ExistingVacations  []VacationPlanJSON    `json:"existing_vacations"`
+	PotentialVacations []PotentialVacation   `json:"potential_vacations"`
+	Balance            *VacationBalance      `json:"balance,omitempty"`
+	Optimization       *VacationOptimization `json:"optimization,omitempty"`
+	Year               int                   `json:"year"`
+	Period             string                `json:"period,omitempty"`
+}
+
+// LifeWeek is one cell of the life calendar. Category is the category
+// covering most of the week, empty when none covers more than half of it.
+type LifeWeek struct {
+	Start    Date
+	End      Date
+	Category string
+}
+
+// LifeCalendar holds the weeks of a life, one row per year of age, and the
+// milestone labels starting in each year of age.
+type LifeCalendar struct {
+	Birth      Date
+	Years      [][]LifeWeek
+	Milestones map[int][]string
+}
+
+// TeamMember is one person of the team view with the leave they booked.
+type TeamMember struct {
+	Name   string
+	Absent map[Date]float64 // leave fraction of each working day away
+}
+
+// TeamConflict is a run of days with more people away than allowed.
+type TeamConflict struct {
+	Start     Date
+	End       Date
+	MaxAbsent int      // most people away on one day of the run
+	People    []string // everyone away on at least one day of the run
+}
+
+// Period is the window of days a calendar is rendered and counted for.
+type Period struct {
+	Start Date // first day
+	End   Date // last day, inclusive
+	Title string
+}
+
+// Contains reports whether the date lies inside the period.
+func (p Period) Contains(date Date) bool {
+	return !date.Before(p.Start) && !date.After(p.End)
+}
+
+// Months returns the first day of every month the period touches.
+func (p Period) Months() []Date {
+	var months []Date
+
+	month := NewDate(p.Start.Year(), p.Start.Month(), 1)
+	for !month.After(p.End) {
+		months = append(months, month)
+		month = month.AddDate(0, 1, 0)
+	}
+
+	return months
+}
+
+// Years returns every calendar year the period touches.
+func (p Period) Years() []int {
+	var years []int
+	for year := p.Start.Year(); year <= p.End.Year(); year++ {
+		years = append(years, year)
+	}
+
+	return years
+}
+
+// WorkSchedule is a rotation of weekly working-day patterns that applies
+// between From and To; zero bounds are open.
+type WorkSchedule struct {
+	From   Date
+	To     Date
+	Anchor Date               // Monday of the first week of the rotation
+	Weeks  []map[int]struct{} // working weekdays per week, Monday = 0
+}
+
+// Covers reports whether the schedule applies on the date.
+func (ws WorkSchedule) Covers(date Date) bool {
+	return (ws.From.IsZero() || !date.Before(ws.From)) &&
+		(ws.To.IsZero() || !date.After(ws.To))
+}
+
+// IsWorkingDay reports whether the schedule has the date as a working day.
+func (ws WorkSchedule) IsWorkingDay(date Date) bool {
+	week := 0
+	if len(ws.Weeks) > 1 {
+		days := date.DaysSince(ws.Anchor)
+		weeks := days / 7
+		if days < 0 && days%7 != 0 {
+			weeks--
+		}
+		week = (weeks%len(ws.Weeks) + len(ws.Weeks)) % len(ws.Weeks)
+	}
+
+	_, working := ws.Weeks[week][(int(date.Weekday())+6)%7]
+	return working
 }
 
 type RenderContext struct {
 	FirstWeekday int              // Monday = 0
 	WeekendDays  map[int]struct{} // {5,6} for Sat/Sun
+	Schedules    []WorkSchedule   // later schedules take precedence
 	MonthNames   map[int]string
 	WeekdayNames []string
 }
+
+// IsWeekend reports whether the date falls on one of the configured weekend days.
+func (ctx *RenderContext) IsWeekend(date Date) bool {
+	weekday := (int(date.Weekday()) + 6) % 7
+	_, isWeekend := ctx.WeekendDays[weekday]
+
+	return isWeekend
+}
+
+// IsWorkingDay reports whether the date is a working day under the schedule
+// in effect, or, without one, whether it is not a weekend day. Holidays are
+// not taken into account.
+func (ctx *RenderContext) IsWorkingDay(date Date) bool {
+	for i := len(ctx.Schedules) - 1; i >= 0; i-- {
+		if ctx.Schedules[i].Covers(date) {
+			return ctx.Schedules[i].IsWorkingDay(date)
+		}
+	}
+
+	return !ctx.IsWeekend(date)
+}
@@ -0,0 +1,61 @@
+package holidays
+
+import "time"
+
+// ruleSets holds the built-in national public holidays by ISO 3166 country code.
+var ruleSets = map[string][]Rule{
+	// Germany, nationwide holidays only.
+	"DE": {
+		Fixed("New Year's Day", time.January, 1),
+		EasterOffset("Good Friday", -2),
+		EasterOffset("Easter Monday", 1),
+		Fixed("Labour Day", time.May, 1),
+		EasterOffset("Ascension Day", 39),
+		EasterOffset("Whit Monday", 50),
+		Fixed("German Unity Day", time.October, 3),
+		Fixed("Christmas Day", time.December, 25),
+		Fixed("Boxing Day", time.December, 26),
+	},
+
+	// France.
+	"FR": {
+		Fixed("New Year's Day", time.January, 1),
+		EasterOffset("Easter Monday", 1),
+		Fixed("Labour Day", time.May, 1),
+		Fixed("Victory in Europe Day", time.May, 8),
+		EasterOffset("Ascension Day", 39),
+		EasterOffset("Whit Monday", 50),
+		Fixed("Bastille Day", time.July, 14),
+		Fixed("Assumption of Mary", time.August, 15),
+		Fixed("All Saints' Day", time.November, 1),
+		Fixed("Armistice Day", time.November, 11),
+		Fixed("Christmas Day", time.December, 25),
+	},
+
+	// United Kingdom, bank holidays of England and Wales.
+	"GB": {
+		Fixed("New Year's Day", time.January, 1).Observed(ObserveMonday),
+		EasterOffset("Good Friday", -2),
+		EasterOffset("Easter Monday", 1),
+		NthWeekday("Early May Bank Holiday", time.May, time.Monday, 1),
+		NthWeekday("Spring Bank Holiday", time.May, time.Monday, -1),
+		NthWeekday("Summer Bank Holiday", time.August, time.Monday, -1),
+		Fixed("Christmas Day", time.December, 25).Observed(ObserveMonday),
+		Fixed("Boxing Day", time.December, 26).Observed(ObserveMonday),
+	},
+
+	// United States, federal holidays.
+	"US": {
+		Fixed("New Year's Day", time.January, 1).Observed(ObserveNearest),
+		NthWeekday("Martin Luther King Jr. Day", time.January, time.Monday, 3).Since(1986),
+		NthWeekday("Washington's Birthday", time.February, time.Monday, 3),
+		NthWeekday("Memorial Day", time.May, time.Monday, -1),
+		Fixed("Juneteenth", time.June, 19).Observed(ObserveNearest).Since(2021),
+		Fixed("Independence Day", time.July, 4).Observed(ObserveNearest),
+		NthWeekday("Labor Day", time.September, time.Monday, 1),
+		NthWeekday("Columbus Day", time.October, time.Monday, 2),
+		Fixed("Veterans Day", time.November, 11).Observed(ObserveNearest),
+		NthWeekday("Thanksgiving Day", time.November, time.Thursday, 4),
+		Fixed("Christmas Day", time.December, 25).Observed(ObserveNearest),
+	},
+}
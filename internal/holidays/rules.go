@@ -0,0 +1,216 @@
+package holidays
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+type ruleKind int
+
+const (
+	kindFixed ruleKind = iota
+	kindEaster
+	kindNthWeekday
+)
+
+// Observance moves a holiday that falls on a weekend to a working day.
+type Observance int
+
+const (
+	// ObserveNone keeps the holiday on its date.
+	ObserveNone Observance = iota
+	// ObserveMonday moves Saturday and Sunday holidays to the next Monday.
+	ObserveMonday
+	// ObserveNearest moves Saturday holidays to Friday and Sunday ones to Monday.
+	ObserveNearest
+)
+
+// Rule describes how to compute one holiday for a given year.
+type Rule struct {
+	Name       string
+	kind       ruleKind
+	month      time.Month
+	day        int
+	offset     int
+	weekday    time.Weekday
+	nth        int
+	observance Observance
+	since      int
+}
+
+// Fixed is a holiday on the same date every year.
+func Fixed(name string, month time.Month, day int) Rule {
+	return Rule{Name: name, kind: kindFixed, month: month, day: day}
+}
+
+// EasterOffset is a holiday a number of days after (or before) Easter Sunday.
+func EasterOffset(name string, days int) Rule {
+	return Rule{Name: name, kind: kindEaster, offset: days}
+}
+
+// NthWeekday is a holiday on the nth weekday of a month. A negative n counts
+// from the end of the month, so -1 is the last such weekday.
+func NthWeekday(name string, month time.Month, weekday time.Weekday, n int) Rule {
+	return Rule{Name: name, kind: kindNthWeekday, month: month, weekday: weekday, nth: n}
+}
+
+// Observed returns a copy of the rule with the given weekend observance.
+func (r Rule) Observed(observance Observance) Rule {
+	r.observance = observance
+	return r
+}
+
+// Since returns a copy of the rule that only applies from the given year on.
+func (r Rule) Since(year int) Rule {
+	r.since = year
+	return r
+}
+
+// Date computes the holiday's actual date in the year, before observance.
+func (r Rule) Date(year int) entity.Date {
+	switch r.kind {
+	case kindEaster:
+		return Easter(year).AddDate(0, 0, r.offset)
+	case kindNthWeekday:
+		return nthWeekday(year, r.month, r.weekday, r.nth)
+	default:
+		return entity.NewDate(year, r.month, r.day)
+	}
+}
+
+// Easter returns Easter Sunday of the Gregorian calendar using the
+// anonymous Gregorian computus (Meeus/Jones/Butcher).
+func Easter(year int) entity.Date {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return entity.NewDate(year, time.Month(month), day)
+}
+
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) entity.Date {
+	if n < 0 {
+		last := entity.NewDate(year, month+1, 0)
+		back := (int(last.Weekday()) - int(weekday) + 7) % 7
+		return last.AddDate(0, 0, -back+7*(n+1))
+	}
+
+	first := entity.NewDate(year, month, 1)
+	ahead := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, ahead+7*(n-1))
+}
+
+// Countries returns the codes of all built-in rule sets.
+func Countries() []string {
+	codes := make([]string, 0, len(ruleSets))
+	for code := range ruleSets {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+// Generate computes the public holidays of a country observed in the year,
+// one single-day entry per holiday labeled with its name, sorted by date.
+func Generate(country string, year int) ([]entity.CategoryEntry, error) {
+	rules, exists := ruleSets[strings.ToUpper(country)]
+	if !exists {
+		return nil, fmt.Errorf(
+			"no holiday rules for country %q, available: %s",
+			country,
+			strings.Join(Countries(), ", "),
+		)
+	}
+
+	// Observance can move a holiday across the year boundary, e.g. New Year's
+	// Day on a Saturday observed on December 31, so neighbours are computed too.
+	type holiday struct {
+		rule Rule
+		date entity.Date
+	}
+	var candidates []holiday
+	for ruleYear := year - 1; ruleYear <= year+1; ruleYear++ {
+		for _, rule := range rules {
+			if rule.since != 0 && ruleYear < rule.since {
+				continue
+			}
+			candidates = append(candidates, holiday{rule: rule, date: rule.Date(ruleYear)})
+		}
+	}
+
+	// Earlier holidays claim their observed day first, so Christmas on a
+	// Sunday takes Monday and Boxing Day moves on to Tuesday.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].date.Before(candidates[j].date)
+	})
+
+	taken := make(map[entity.Date]struct{})
+	var entries []entity.CategoryEntry
+	for _, candidate := range candidates {
+		date := observe(candidate.date, candidate.rule.observance, taken)
+		taken[date] = struct{}{}
+
+		if date.Year() != year {
+			continue
+		}
+
+		entries = append(entries, entity.CategoryEntry{
+			DateStart: date,
+			DateEnd:   date,
+			Label:     candidate.rule.Name,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DateStart.Before(entries[j].DateStart)
+	})
+
+	return entries, nil
+}
+
+// observe applies the weekend observance. When the observed day, or the
+// weekday the holiday falls on, is already taken by another holiday, the next
+// free working day is used instead, e.g. Boxing Day moves to Tuesday when
+// Christmas is observed on Monday.
+func observe(date entity.Date, observance Observance, taken map[entity.Date]struct{}) entity.Date {
+	if observance == ObserveNone {
+		return date
+	}
+
+	observed := date
+	switch date.Weekday() {
+	case time.Saturday:
+		if observance == ObserveNearest {
+			observed = date.AddDate(0, 0, -1)
+		} else {
+			observed = date.AddDate(0, 0, 2)
+		}
+	case time.Sunday:
+		observed = date.AddDate(0, 0, 1)
+	}
+
+	for {
+		_, isTaken := taken[observed]
+		isWeekend := observed.Weekday() == time.Saturday || observed.Weekday() == time.Sunday
+		if !isTaken && !isWeekend {
+			return observed
+		}
+		observed = observed.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,434 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+const (
+	localDateTimeLayout = "20060102T150405"
+
+	// maxOccurrences bounds the expansion of open-ended recurrences.
+	maxOccurrences = 100000
+)
+
+// Recurrence is the subset of an RRULE that can be expanded: a frequency
+// with an optional interval, count and end date.
+type Recurrence struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    entity.Date
+	// Unsupported lists RRULE parts that are not understood. Events with
+	// unsupported parts only yield their first occurrence.
+	Unsupported []string
+}
+
+// eventState collects the properties of the VEVENT being decoded.
+type eventState struct {
+	event        Event
+	hasEnd       bool
+	durationDays int
+}
+
+// content is a single unfolded content line.
+type content struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode parses the VEVENTs of an iCalendar document. Timed events are
+// converted to the all-day dates they touch in the local time zone.
+func Decode(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	var events []Event
+	var current *eventState
+	depth := 0
+
+	for lineNum, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		prop, parseErr := parseContentLine(line)
+		if parseErr != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum+1, parseErr)
+		}
+
+		switch {
+		case prop.name == "BEGIN" && prop.value == "VEVENT":
+			current = &eventState{}
+			depth = 0
+		case current == nil:
+			continue
+		case prop.name == "BEGIN":
+			depth++
+		case prop.name == "END" && prop.value == "VEVENT":
+			event, finishErr := current.finish()
+			if finishErr != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum+1, finishErr)
+			}
+			events = append(events, event)
+			current = nil
+		case prop.name == "END":
+			depth--
+		case depth > 0:
+			// Properties of nested components such as VALARM.
+			continue
+		default:
+			if propErr := current.apply(prop); propErr != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum+1, propErr)
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// apply stores a VEVENT property.
+func (st *eventState) apply(prop content) error {
+	switch prop.name {
+	case "UID":
+		st.event.UID = unescapeText(prop.value)
+	case "SUMMARY":
+		st.event.Summary = unescapeText(prop.value)
+	case "CATEGORIES":
+		for _, category := range splitEscaped(prop.value) {
+			st.event.Categories = append(st.event.Categories, unescapeText(category))
+		}
+	case "DTSTART":
+		date, _, err := parseDateValue(prop)
+		if err != nil {
+			return fmt.Errorf("invalid DTSTART: %w", err)
+		}
+		st.event.DateStart = date
+	case "DTEND":
+		date, allDay, err := parseDateValue(prop)
+		if err != nil {
+			return fmt.Errorf("invalid DTEND: %w", err)
+		}
+		st.event.DateEnd = exclusiveEnd(date, allDay, prop)
+		st.hasEnd = true
+	case "DURATION":
+		days, err := parseDurationDays(prop.value)
+		if err != nil {
+			return fmt.Errorf("invalid DURATION: %w", err)
+		}
+		st.durationDays = days
+	case "RRULE":
+		recurrence, err := parseRecurrence(prop.value)
+		if err != nil {
+			return fmt.Errorf("invalid RRULE: %w", err)
+		}
+		st.event.Recurrence = recurrence
+	}
+
+	return nil
+}
+
+// finish resolves the end date once all properties are known.
+func (st *eventState) finish() (Event, error) {
+	event := st.event
+	if event.DateStart.IsZero() {
+		return Event{}, fmt.Errorf("event %q has no DTSTART", event.UID)
+	}
+
+	switch {
+	case st.hasEnd:
+	case st.durationDays > 0:
+		event.DateEnd = event.DateStart.AddDate(0, 0, st.durationDays-1)
+	default:
+		event.DateEnd = event.DateStart
+	}
+
+	if event.DateEnd.Before(event.DateStart) {
+		event.DateEnd = event.DateStart
+	}
+
+	return event, nil
+}
+
+// exclusiveEnd converts DTEND to an inclusive end date. All-day DTEND values
+// are exclusive; timed events ending exactly at midnight end the day before.
+func exclusiveEnd(date entity.Date, allDay bool, prop content) entity.Date {
+	if allDay {
+		return date.AddDate(0, 0, -1)
+	}
+
+	if t, err := parseDateTime(prop); err == nil &&
+		t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return date.AddDate(0, 0, -1)
+	}
+
+	return date
+}
+
+// Occurrences returns all occurrences of the event that overlap [from, to].
+func (e Event) Occurrences(from, to entity.Date) []Event {
+	length := e.DateEnd.DaysSince(e.DateStart)
+
+	var occurrences []Event
+	add := func(start entity.Date) {
+		occurrence := e
+		occurrence.Recurrence = nil
+		occurrence.DateStart = start
+		occurrence.DateEnd = start.AddDate(0, 0, length)
+		if !occurrence.DateStart.After(to) && !occurrence.DateEnd.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	rule := e.Recurrence
+	if rule == nil || len(rule.Unsupported) > 0 {
+		add(e.DateStart)
+		return occurrences
+	}
+
+	interval := max(rule.Interval, 1)
+	emitted := 0
+	for i := 0; i < maxOccurrences; i++ {
+		start, ok := nthOccurrence(e.DateStart, rule.Freq, i*interval)
+		if start.After(to) || (!rule.Until.IsZero() && start.After(rule.Until)) {
+			break
+		}
+		if !ok {
+			continue
+		}
+
+		add(start)
+		emitted++
+		if rule.Count > 0 && emitted >= rule.Count {
+			break
+		}
+	}
+
+	return occurrences
+}
+
+// nthOccurrence steps n frequency units from start. Monthly and yearly steps
+// that land on a non-existent day (e.g. February 30) are reported as not ok.
+func nthOccurrence(start entity.Date, freq string, n int) (entity.Date, bool) {
+	switch freq {
+	case "DAILY":
+		return start.AddDate(0, 0, n), true
+	case "WEEKLY":
+		return start.AddDate(0, 0, 7*n), true
+	case "MONTHLY":
+		next := start.AddDate(0, n, 0)
+		return next, next.Day() == start.Day()
+	default: // YEARLY
+		next := start.AddDate(n, 0, 0)
+		return next, next.Day() == start.Day()
+	}
+}
+
+func parseRecurrence(value string) (*Recurrence, error) {
+	rule := &Recurrence{Interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("malformed part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch strings.ToUpper(val) {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.Freq = strings.ToUpper(val)
+			default:
+				rule.Unsupported = append(rule.Unsupported, part)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", val)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, _, err := parseDateValue(content{value: val, params: map[string]string{}})
+			if err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", val)
+			}
+			rule.Until = until
+		case "WKST":
+			// Only relevant together with BYxxx parts.
+		default:
+			rule.Unsupported = append(rule.Unsupported, part)
+		}
+	}
+
+	if rule.Freq == "" && len(rule.Unsupported) == 0 {
+		return nil, errors.New("missing FREQ")
+	}
+
+	return rule, nil
+}
+
+// parseDateValue returns the local calendar date of a DATE or DATE-TIME value
+// and whether it was a plain DATE.
+func parseDateValue(prop content) (entity.Date, bool, error) {
+	value := strings.TrimSpace(prop.value)
+
+	if prop.params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		date, err := entity.ParseDateFormat(dateLayout, value)
+		return date, true, err
+	}
+
+	t, err := parseDateTime(prop)
+	if err != nil {
+		return entity.Date{}, false, err
+	}
+
+	return entity.DateOf(t.In(time.Local)), false, nil
+}
+
+func parseDateTime(prop content) (time.Time, error) {
+	value := strings.TrimSpace(prop.value)
+
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(dateTimeLayout, value)
+	}
+
+	location := time.Local
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+
+	return time.ParseInLocation(localDateTimeLayout, value, location)
+}
+
+// parseDurationDays returns the number of days touched by a DURATION such
+// as P1D, P2W or PT3H. Durations shorter than a day count as one day.
+func parseDurationDays(value string) (int, error) {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	datePart, _, _ := strings.Cut(value, "T")
+
+	days := 0
+	number := ""
+	for _, r := range datePart {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+		case r == 'W' || r == 'D':
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("malformed duration %q", value)
+			}
+			if r == 'W' {
+				n *= 7
+			}
+			days += n
+			number = ""
+		default:
+			return 0, fmt.Errorf("malformed duration %q", value)
+		}
+	}
+
+	return max(days, 1), nil
+}
+
+// unfold reads content lines, joining folded continuation lines.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+func parseContentLine(line string) (content, error) {
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return content{}, fmt.Errorf("malformed content line %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := content{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+
+	return prop, nil
+}
+
+// splitEscaped splits a TEXT list on commas that are not escaped.
+func splitEscaped(value string) []string {
+	var items []string
+	var current strings.Builder
+	escaped := false
+
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			items = append(items, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	return append(items, current.String())
+}
+
+func unescapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	)
+
+	return replacer.Replace(value)
+}
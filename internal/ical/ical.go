@@ -0,0 +1,105 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+	maxLineOctets  = 75
+	productID      = "-//nsr888//lifecalendar//EN"
+)
+
+// Event is an all-day calendar event. DateEnd is inclusive.
+type Event struct {
+	UID        string
+	Summary    string
+	Categories []string
+	DateStart  entity.Date
+	DateEnd    entity.Date
+	// Recurrence is set for decoded events with an RRULE; Encode ignores it.
+	Recurrence *Recurrence
+}
+
+// Encode writes events as an iCalendar (RFC 5545) document with all-day
+// VEVENTs. DTEND is written exclusive, as the standard requires.
+func Encode(w io.Writer, events []Event, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+productID)
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "METHOD:PUBLISH")
+
+	dtstamp := stamp.UTC().Format(dateTimeLayout)
+	for _, event := range events {
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+escapeText(event.UID))
+		writeLine(bw, "DTSTAMP:"+dtstamp)
+		writeLine(bw, "DTSTART;VALUE=DATE:"+event.DateStart.Format(dateLayout))
+		writeLine(bw, "DTEND;VALUE=DATE:"+event.DateEnd.AddDate(0, 0, 1).Format(dateLayout))
+		writeLine(bw, "SUMMARY:"+escapeText(event.Summary))
+		if len(event.Categories) > 0 {
+			escaped := make([]string, len(event.Categories))
+			for i, category := range event.Categories {
+				escaped[i] = escapeText(category)
+			}
+			writeLine(bw, "CATEGORIES:"+strings.Join(escaped, ","))
+		}
+		writeLine(bw, "TRANSP:TRANSPARENT")
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+
+	return nil
+}
+
+// writeLine writes a content line terminated by CRLF, folding it so that no
+// physical line exceeds 75 octets and multi-byte characters are not split.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// escapeText escapes a TEXT property value.
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+
+	return replacer.Replace(value)
+}
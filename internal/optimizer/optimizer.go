@@ -0,0 +1,210 @@
+package optimizer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+// Day is one day the optimizer may place vacation on.
+type Day struct {
+	Date entity.Date
+	// Off marks weekends and public holidays, free days off.
+	Off bool
+	// Blocked marks days that must not be part of a new block, such as
+	// days already covered by existing plans.
+	Blocked bool
+}
+
+// Constraints limit the placements the optimizer considers.
+type Constraints struct {
+	Budget       int          // vacation days to spend
+	AvoidMonths  []time.Month // no block touches these months
+	MinBlockDays int          // minimum consecutive days off per block
+	MaxBlocks    int          // 0 means unlimited
+}
+
+// candidate is a block of consecutive days that starts and ends next to a
+// working day, so it cannot be extended for free.
+type candidate struct {
+	start, end int
+	cost       int
+}
+
+// Optimize places at most Budget vacation days on the working days of the
+// consecutive days given, maximising the total days off of the resulting
+// blocks. Each block absorbs the weekends and holidays around it. Blocks are
+// separated by at least one working day and returned ranked by efficiency.
+func Optimize(days []Day, constraints Constraints) entity.VacationOptimization {
+	result := entity.VacationOptimization{
+		Budget: constraints.Budget,
+		Blocks: []entity.OptimizedBlock{},
+	}
+	if constraints.Budget <= 0 || len(days) == 0 {
+		return result
+	}
+
+	candidates := findCandidates(days, constraints)
+	chosen := selectCandidates(candidates, constraints)
+
+	for _, c := range chosen {
+		length := c.end - c.start + 1
+		result.Blocks = append(result.Blocks, entity.OptimizedBlock{
+			DateStart:    days[c.start].Date.Format("2006-01-02"),
+			DateEnd:      days[c.end].Date.Format("2006-01-02"),
+			VacationDays: c.cost,
+			TotalDays:    length,
+			Efficiency:   efficiency(length, c.cost),
+		})
+		result.VacationDays += c.cost
+		result.TotalDays += length
+	}
+
+	sort.SliceStable(result.Blocks, func(i, j int) bool {
+		if result.Blocks[i].Efficiency == result.Blocks[j].Efficiency {
+			return result.Blocks[i].DateStart < result.Blocks[j].DateStart
+		}
+		return result.Blocks[i].Efficiency > result.Blocks[j].Efficiency
+	})
+
+	result.Efficiency = efficiency(result.TotalDays, result.VacationDays)
+
+	return result
+}
+
+// findCandidates lists every block that costs between one and Budget
+// vacation days, honoring avoided months, blocked days and the minimum length.
+func findCandidates(days []Day, constraints Constraints) []candidate {
+	avoided := make(map[time.Month]struct{}, len(constraints.AvoidMonths))
+	for _, month := range constraints.AvoidMonths {
+		avoided[month] = struct{}{}
+	}
+
+	usable := func(i int) bool {
+		_, isAvoided := avoided[days[i].Date.Month()]
+		return !days[i].Blocked && !isAvoided
+	}
+	freeOff := func(i int) bool {
+		return i >= 0 && i < len(days) && days[i].Off && usable(i)
+	}
+
+	var candidates []candidate
+	for start := range days {
+		// A block starting in the middle of a run of days off could be
+		// extended backwards for free.
+		if !usable(start) || freeOff(start-1) {
+			continue
+		}
+
+		cost := 0
+		for end := start; end < len(days) && usable(end); end++ {
+			if !days[end].Off {
+				cost++
+			}
+			if cost > constraints.Budget {
+				break
+			}
+			if cost == 0 || freeOff(end+1) {
+				continue
+			}
+			if end-start+1 >= constraints.MinBlockDays {
+				candidates = append(candidates, candidate{start: start, end: end, cost: cost})
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].end < candidates[j].end
+	})
+
+	return candidates
+}
+
+// selectCandidates picks the non-adjacent candidates with the most days off
+// within the budget and block limit, preferring fewer vacation days on ties.
+func selectCandidates(candidates []candidate, constraints Constraints) []candidate {
+	budget := constraints.Budget
+	blockSlots := 1
+	if constraints.MaxBlocks > 0 {
+		blockSlots = constraints.MaxBlocks
+	}
+
+	// previous[k] is the number of candidates that end before candidate k
+	// starts with a gap day in between, i.e. the prefix it may follow.
+	previous := make([]int, len(candidates))
+	for k, c := range candidates {
+		previous[k] = sort.Search(len(candidates), func(i int) bool {
+			return candidates[i].end >= c.start-1
+		})
+	}
+
+	// best[k][b][n] is the highest score using the first k candidates with
+	// at most b vacation days and at most n+1 blocks (or unlimited blocks).
+	width := (budget + 1) * blockSlots
+	best := make([][]int, len(candidates)+1)
+	best[0] = make([]int, width)
+	index := func(b, n int) int { return b*blockSlots + n }
+
+	for k, c := range candidates {
+		row := make([]int, width)
+		copy(row, best[k])
+		gain := score(c)
+
+		for b := c.cost; b <= budget; b++ {
+			for n := range blockSlots {
+				var prior int
+				switch {
+				case constraints.MaxBlocks == 0:
+					prior = best[previous[k]][index(b-c.cost, 0)]
+				case n == 0:
+					prior = 0
+				default:
+					prior = best[previous[k]][index(b-c.cost, n-1)]
+				}
+
+				if prior+gain > row[index(b, n)] {
+					row[index(b, n)] = prior + gain
+				}
+			}
+		}
+
+		best[k+1] = row
+	}
+
+	var chosen []candidate
+	b, n := budget, blockSlots-1
+	for k := len(candidates); k > 0; {
+		if best[k][index(b, n)] == best[k-1][index(b, n)] {
+			k--
+			continue
+		}
+
+		c := candidates[k-1]
+		chosen = append(chosen, c)
+		b -= c.cost
+		if constraints.MaxBlocks > 0 {
+			if n == 0 {
+				break
+			}
+			n--
+		}
+		k = previous[k-1]
+	}
+
+	return chosen
+}
+
+// score ranks days off first and saves vacation days on ties.
+func score(c candidate) int {
+	const daysOffWeight = 1000
+	return (c.end-c.start+1)*daysOffWeight - c.cost
+}
+
+func efficiency(daysOff, vacationDays int) float64 {
+	if vacationDays == 0 {
+		return 0
+	}
+
+	return float64(int(float64(daysOff)/float64(vacationDays)*100+0.5)) / 100
+}
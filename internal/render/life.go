@@ -0,0 +1,104 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nsr888/lifecalendar/internal/calendar"
+	"github.com/nsr888/lifecalendar/internal/entity"
+	"github.com/nsr888/lifecalendar/pkg/colors"
+)
+
+// RenderLifeView prints the life calendar: a row of weekly cells per year of
+// age, lived weeks filled, weeks covered by a category in its colour, and
+// the milestones of each year next to its row.
+func (rs *Service) RenderLifeView(life entity.LifeCalendar) {
+	today := entity.Today()
+	milestoneWidth := rs.maxWidthInChars - 4 - calendar.WeeksPerLifeYear - 2
+
+	// Number the first week and every tenth one.
+	header := []byte(strings.Repeat(" ", calendar.WeeksPerLifeYear))
+	header[0] = '1'
+	for week := 10; week < calendar.WeeksPerLifeYear; week += 10 {
+		copy(header[week-1:], strconv.Itoa(week))
+	}
+	fmt.Println(colors.Text().Render("Age " + string(header)))
+
+	usedCategories := make(map[string]struct{})
+
+	for age, weeks := range life.Years {
+		var row strings.Builder
+		row.WriteString(colors.Text().Render(fmt.Sprintf("%3d ", age)))
+
+		for _, week := range weeks {
+			if week.Category != "" {
+				usedCategories[week.Category] = struct{}{}
+			}
+			row.WriteString(rs.lifeWeekCell(week, today))
+		}
+
+		if milestones := life.Milestones[age]; len(milestones) > 0 && milestoneWidth > 0 {
+			text := strings.Join(milestones, ", ")
+			if len([]rune(text)) > milestoneWidth {
+				text = string([]rune(text)[:milestoneWidth-1]) + "…"
+			}
+			row.WriteString("  " + colors.Text().Render(text))
+		}
+
+		fmt.Println(row.String())
+	}
+
+	fmt.Println(rs.generateLifeLegendLines(usedCategories))
+}
+
+// lifeWeekCell renders a week covered by a category in its colour, the
+// current week highlighted, lived weeks filled and the rest empty.
+func (rs *Service) lifeWeekCell(week entity.LifeWeek, today entity.Date) string {
+	switch {
+	case !today.Before(week.Start) && !today.After(week.End):
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#cc0000")).Render("■")
+	case week.Category != "":
+		return lipgloss.NewStyle().Foreground(rs.categoryColor(week.Category)).Render("■")
+	case week.End.Before(today):
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#909090")).Render("■")
+	default:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#4d4d4d")).Render("□")
+	}
+}
+
+// categoryColor is the background of the category, or its foreground when
+// it has no background.
+func (rs *Service) categoryColor(category string) lipgloss.TerminalColor {
+	style := rs.styleService.GetCategoryStyle(category)
+	if color := style.GetBackground(); color != (lipgloss.NoColor{}) {
+		return color
+	}
+
+	return style.GetForeground()
+}
+
+func (rs *Service) generateLifeLegendLines(categories map[string]struct{}) string {
+	if len(categories) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines strings.Builder
+	lines.WriteString(colors.Header().Render("Legend:") + "\n")
+	for _, name := range names {
+		cell := lipgloss.NewStyle().Foreground(rs.categoryColor(name)).Render("■")
+		lines.WriteString(fmt.Sprintf("%s %s  ", cell, colors.Text().Render(
+			strings.ReplaceAll(name, "_", " "),
+		)))
+	}
+
+	return lines.String()
+}
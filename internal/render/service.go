@@ -5,7 +5,6 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/list"
@@ -28,32 +27,34 @@ type DateCategoryInfo struct {
 
 // Service provides calendar rendering functionality with unified style management.
 type Service struct {
-	year            int
+	period          entity.Period
 	config          *entity.CategoryName
 	appConfig       *config.Config
 	ctx             *entity.RenderContext
 	styleService    styles.StyleService
+	balance         *entity.VacationBalance
 	maxWidthInChars int
 	monthWidth      int
 	separatorWidth  int
 }
 
 func NewService(
-	year int,
+	period entity.Period,
 	cfg *entity.CategoryName,
 	appConfig *config.Config,
+	ctx *entity.RenderContext,
 	styleService styles.StyleService,
 ) *Service {
 	rs := &Service{
-		year:            year,
+		period:          period,
 		config:          cfg,
 		appConfig:       appConfig,
-		ctx:             calendar.NewRenderContext(),
+		ctx:             ctx,
 		styleService:    styleService,
 		maxWidthInChars: 80,
-		monthWidth:      20,
 		separatorWidth:  2,
 	}
+	rs.monthWidth = 20 + rs.weekNumberWidth()
 
 	return rs
 }
@@ -63,7 +64,51 @@ func (rs *Service) SetMaxWidth(maxWidth int) {
 		maxWidth = 20
 	}
 	rs.maxWidthInChars = maxWidth
-	rs.monthWidth = 20
+	rs.monthWidth = 20 + rs.weekNumberWidth()
+}
+
+// weekNumberWidth returns the width of the week-number gutter, 0 when it is off.
+func (rs *Service) weekNumberWidth() int {
+	if !rs.appConfig.Rendering.WeekNumbers {
+		return 0
+	}
+
+	return 3
+}
+
+// weekNumberCell renders the gutter cell of the calendar row containing the
+// date, followed by a space. It returns "" when week numbers are off.
+func (rs *Service) weekNumberCell(date entity.Date) string {
+	if rs.weekNumberWidth() == 0 {
+		return ""
+	}
+
+	return rs.weekNumberStyle().Render(
+		fmt.Sprintf("%2d", calendar.RowISOWeek(date, rs.ctx.FirstWeekday)),
+	) + " "
+}
+
+// weekNumberHeader is the gutter cell of the weekday header row.
+func (rs *Service) weekNumberHeader() string {
+	if rs.weekNumberWidth() == 0 {
+		return ""
+	}
+
+	return rs.weekNumberStyle().Render("Wk") + " "
+}
+
+// weekNumberStyle uses the week_number category when configured, a dim gray otherwise.
+func (rs *Service) weekNumberStyle() lipgloss.Style {
+	if _, exists := rs.appConfig.Categories[string(entity.CategoryWeekNumber)]; exists {
+		return rs.styleService.GetCategoryStyle(string(entity.CategoryWeekNumber))
+	}
+
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#595959"))
+}
+
+// SetBalance sets the vacation balance shown with the statistics; nil hides it.
+func (rs *Service) SetBalance(balance *entity.VacationBalance) {
+	rs.balance = balance
 }
 
 func (rs *Service) calculateColumnsPerWidth() int {
@@ -98,12 +143,13 @@ func (rs *Service) calculateLayout() (bool, int, int) {
 	return useSidePanel, calendarCols, sidePanelWidth
 }
 
-func (rs *Service) RenderYearTitle(year int) {
+// RenderTitle prints the period title, e.g. "2025" or "FY2025/26".
+func (rs *Service) RenderTitle() {
 	borderString := strings.Repeat("─", rs.maxWidthInChars)
 	borderStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#595959"))
 	fmt.Println(borderStyle.Render(borderString))
 
-	title := strconv.Itoa(year)
+	title := rs.period.Title
 	titleStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#d8d8d8")).
 		Bold(true).
@@ -115,22 +161,33 @@ func (rs *Service) RenderYearTitle(year int) {
 }
 
 func (rs *Service) computeMonthBlocks() [][]string {
-	allMonths := make([][]string, 12)
-	for m := 1; m <= 12; m++ {
-		name := rs.ctx.MonthNames[m]
-		if name == "" {
-			name = time.Month(m).String()
-		}
-
-		calData := calendar.MonthCalendar(rs.year, time.Month(m))
-		lines := rs.generateMonthLines(name, calData, time.Month(m))
-		allMonths[m-1] = lines
+	months := rs.period.Months()
+	allMonths := make([][]string, len(months))
+	for i, month := range months {
+		name := rs.monthTitle(month, len(months))
+		calData := calendar.MonthCalendar(month.Year(), month.Month(), rs.ctx.FirstWeekday)
+		lines := rs.generateMonthLines(name, calData, month)
+		allMonths[i] = lines
 	}
 	return allMonths
 }
 
-func (rs *Service) getDayDisplay(dayDate time.Time) string {
-	if dayDate.Year() != rs.year {
+// monthTitle names the month, adding the year when it differs from the
+// period's first year or the period is longer than a year.
+func (rs *Service) monthTitle(month entity.Date, monthCount int) string {
+	name := rs.ctx.MonthNames[int(month.Month())]
+	if name == "" {
+		name = month.Month().String()
+	}
+	if month.Year() != rs.period.Start.Year() || monthCount > 12 {
+		name += " " + strconv.Itoa(month.Year())
+	}
+
+	return name
+}
+
+func (rs *Service) getDayDisplay(dayDate entity.Date) string {
+	if !rs.period.Contains(dayDate) {
 		return "  "
 	}
 
@@ -144,21 +201,40 @@ func (rs *Service) getDayDisplay(dayDate time.Time) string {
 	if info, exists := rs.styleService.GetDayStyle(dayDate); exists {
 		categoryStyle := rs.styleService.GetCategoryStyle(info.Category)
 
-		style = categoryStyle.
-			Width(2).
-			Align(lipgloss.Right)
-
-		return style.Render(dayNum)
+		return renderDayPortion(fmt.Sprintf("%2s", dayNum), categoryStyle, style, info.Portion)
 	}
 
 	return style.Render(dayNum)
 }
 
+// renderDayPortion renders a two-character day cell. Half days are split:
+// the morning colors the left half and the afternoon the right half, while
+// an unspecified half day is dimmed.
+func renderDayPortion(
+	cell string,
+	categoryStyle, plainStyle lipgloss.Style,
+	portion entity.DayPortion,
+) string {
+	categoryStyle = categoryStyle.UnsetWidth().UnsetAlign()
+	plainStyle = plainStyle.UnsetWidth().UnsetAlign()
+
+	switch portion {
+	case entity.PortionMorning:
+		return categoryStyle.Render(cell[:1]) + plainStyle.Render(cell[1:])
+	case entity.PortionAfternoon:
+		return plainStyle.Render(cell[:1]) + categoryStyle.Render(cell[1:])
+	case entity.PortionHalf:
+		return categoryStyle.Faint(true).Render(cell)
+	default:
+		return categoryStyle.Render(cell)
+	}
+}
+
 // generateMonthLines creates the text lines for a single month.
 func (rs *Service) generateMonthLines(
 	name string,
 	calData [][]int,
-	month time.Month,
+	month entity.Date,
 ) []string {
 	var lines []string
 	monthHeaderStyle := lipgloss.NewStyle().
@@ -172,20 +248,26 @@ func (rs *Service) generateMonthLines(
 	weekdayHeaderStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#4d4d4d")).
 		Bold(false)
-	weekdayHeader := weekdayHeaderStyle.Render("Mo Tu We Th Fr Sa Su")
-	lines = append(lines, weekdayHeader)
+	weekdayHeader := weekdayHeaderStyle.Render(
+		strings.Join(calendar.WeekdayHeader(rs.ctx), " "),
+	)
+	lines = append(lines, rs.weekNumberHeader()+weekdayHeader)
 
 	for _, week := range calData {
 		var cells []string
+		var rowDate entity.Date
 		for _, dayNum := range week {
 			if dayNum == 0 {
 				cells = append(cells, "  ")
 				continue
 			}
-			d := time.Date(rs.year, month, dayNum, 0, 0, 0, 0, time.Local)
+			d := entity.NewDate(month.Year(), month.Month(), dayNum)
+			if rowDate.IsZero() {
+				rowDate = d
+			}
 			cells = append(cells, rs.getDayDisplay(d))
 		}
-		line := strings.Join(cells, " ")
+		line := rs.weekNumberCell(rowDate) + strings.Join(cells, " ")
 		lines = append(lines, line)
 	}
 	return lines
@@ -244,7 +326,11 @@ func (rs *Service) generateLegendLines() string {
 			continue
 		}
 
-		displayName := strings.ReplaceAll(categoryName, "_", " ")
+		displayName := categoryName
+		if category.Desc != "" {
+			displayName = category.Desc
+		}
+		displayName = strings.ReplaceAll(displayName, "_", " ")
 		legendItems = append(legendItems, legendItem{
 			name:  displayName,
 			style: style,
@@ -305,29 +391,12 @@ func (rs *Service) generateCategoriesLines(
 }
 
 // calculateCategoryStats calculates statistics for all categories, considering priority.
-func (rs *Service) calculateCategoryStats() map[string]int {
-	stats := make(map[string]int)
-
-	startDate := time.Date(rs.year, 1, 1, 0, 0, 0, 0, time.Local)
-	endDate := time.Date(rs.year, 12, 31, 0, 0, 0, 0, time.Local)
-
-	for currentDate := startDate; !currentDate.After(endDate); currentDate = currentDate.AddDate(0, 0, 1) {
-		var winningCategory string
-		winningPriority := 999
-
-		for categoryName, category := range rs.config.Categories {
-			if _, exists := category.Dates[currentDate]; exists {
-				categoryConfig := rs.appConfig.GetCategoryConfig(categoryName)
-				if categoryConfig.Priority < winningPriority {
-					winningCategory = categoryName
-					winningPriority = categoryConfig.Priority
-				}
-			}
-		}
+func (rs *Service) calculateCategoryStats() map[string]float64 {
+	stats := make(map[string]float64)
 
-		if winningCategory != "" {
-			stats[winningCategory]++
-		}
+	for _, segment := range rs.styleService.GetDayIndex().Segments(rs.period.Start, rs.period.End) {
+		days := float64(segment.End.DaysSince(segment.Start) + 1)
+		stats[segment.Info.Category] += days * segment.Info.Portion.Fraction()
 	}
 
 	return stats
@@ -348,7 +417,7 @@ func (rs *Service) generateStatsLines(width int) string {
 	type categoryStat struct {
 		name     string
 		priority int
-		days     int
+		days     float64
 	}
 
 	var sortedStats []categoryStat
@@ -372,16 +441,52 @@ func (rs *Service) generateStatsLines(width int) string {
 		if stat.days > 0 {
 			displayName := strings.ReplaceAll(stat.name, "_", " ")
 			displayName = cases.Title(language.English).String(displayName)
-			line := fmt.Sprintf("%s: %d", displayName, stat.days)
+			line := fmt.Sprintf("%s: %s", displayName, formatDays(stat.days))
 			l.Item(line)
 		}
 	}
 
 	lines.WriteString(l.String() + "\n")
 
+	if rs.balance != nil {
+		lines.WriteString(rs.generateBalanceLines(width))
+	}
+
 	return lines.String()
 }
 
+// generateBalanceLines lists the vacation allowance of the period in days.
+func (rs *Service) generateBalanceLines(width int) string {
+	var lines strings.Builder
+
+	lines.WriteString(colors.Header().Render("Allowance:") + "\n")
+
+	l := list.New().
+		Enumerator(list.Bullet).
+		EnumeratorStyle(colors.Text().MarginRight(1)).
+		ItemStyle(colors.Text().Width(width - 4))
+
+	l.Item("Entitlement: " + formatDays(rs.balance.Entitlement))
+	if rs.balance.CarriedOver > 0 {
+		l.Item("Carried Over: " + formatDays(rs.balance.CarriedOver))
+	}
+	if rs.balance.Expired > 0 {
+		l.Item("Expired: " + formatDays(rs.balance.Expired))
+	}
+	l.Item("Used: " + formatDays(rs.balance.Used))
+	l.Item("Planned: " + formatDays(rs.balance.Planned))
+	l.Item("Remaining: " + formatDays(rs.balance.Remaining))
+
+	lines.WriteString(l.String() + "\n")
+
+	return lines.String()
+}
+
+// formatDays prints a day count without trailing zeros, e.g. 3 or 2.5.
+func formatDays(days float64) string {
+	return strconv.FormatFloat(days, 'f', -1, 64)
+}
+
 // RenderCompactYearView renders the calendar in a compact grid with configurable columns.
 func (rs *Service) RenderCompactYearView() {
 	rs.RenderCompactYearViewWithSidePanel(nil)
@@ -443,39 +548,14 @@ func (rs *Service) generateMonthNamesColumn() string {
 
 	lines = append(lines, "")
 
-	currentDate := time.Date(rs.year, 1, 1, 0, 0, 0, 0, time.Local)
-
-	// Calculate starting weekday offset
-	startWeekday := int(currentDate.Weekday())
-	if rs.appConfig.Rendering.FirstWeekday == 0 { // Monday as first day
-		startWeekday = (startWeekday - 1 + 7) % 7
-	}
-
-	// Add empty lines for initial offset to align with first week
-	for i := 0; i < startWeekday; i++ {
-		lines = append(lines, "")
-	}
-
 	// Add month names at the start of each month
-	for month := 1; month <= 12; month++ {
-		firstDayOfMonth := time.Date(
-			rs.year,
-			time.Month(month),
-			1,
-			0,
-			0,
-			0,
-			0,
-			time.Local,
-		)
+	for _, firstDayOfMonth := range rs.period.Months() {
+		if firstDayOfMonth.Before(rs.period.Start) {
+			firstDayOfMonth = rs.period.Start
+		}
 
 		// Calculate which week line this month starts on
-		daysSinceStart := int(
-			firstDayOfMonth.Sub(time.Date(rs.year, 1, 1, 0, 0, 0, 0, time.Local)).
-				Hours() /
-				24,
-		)
-		weekIndex := 1 + (startWeekday+daysSinceStart)/7 // +1 for header line
+		weekIndex := rs.calendarWeekRow(firstDayOfMonth) // header line is row 0
 
 		// Ensure we have enough lines
 		for len(lines) <= weekIndex {
@@ -501,23 +581,16 @@ func (rs *Service) generateMonthNamesColumn() string {
 func (rs *Service) generateContinuousCalendarColumn() string {
 	var lines []string
 
-	weekdayNames := []string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}
-	if rs.appConfig.Rendering.FirstWeekday == 0 {
-		weekdayNames = []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"}
-	}
-
-	header := strings.Join(weekdayNames, " ")
+	header := strings.Join(calendar.WeekdayHeader(rs.ctx), " ")
 	header = colors.Text().Render(header)
-	lines = append(lines, header)
+	lines = append(lines, rs.weekNumberHeader()+header)
 
-	// Start from first day of year
-	currentDate := time.Date(rs.year, 1, 1, 0, 0, 0, 0, time.Local)
+	// Start from first day of the period
+	currentDate := rs.period.Start
+	rowDate := currentDate
 
 	// Calculate starting weekday offset
-	startWeekday := int(currentDate.Weekday())
-	if rs.appConfig.Rendering.FirstWeekday == 0 {
-		startWeekday = (startWeekday - 1 + 7) % 7
-	}
+	startWeekday := calendar.WeekdayColumn(currentDate, rs.ctx.FirstWeekday)
 
 	// Create the first week with leading spaces
 	var weekDays []string
@@ -525,14 +598,17 @@ func (rs *Service) generateContinuousCalendarColumn() string {
 		weekDays = append(weekDays, "  ")
 	}
 
-	// Generate all days of the year
-	for currentDate.Year() == rs.year {
+	// Generate all days of the period
+	for !currentDate.After(rs.period.End) {
+		if len(weekDays) == 0 {
+			rowDate = currentDate
+		}
 		dayStr := fmt.Sprintf("%2d", currentDate.Day())
 
 		// Apply styling if the day has a category
 		if info, exists := rs.styleService.GetDayStyle(currentDate); exists {
 			categoryStyle := rs.styleService.GetCategoryStyle(info.Category)
-			dayStr = categoryStyle.Render(dayStr)
+			dayStr = renderDayPortion(dayStr, categoryStyle, colors.Text(), info.Portion)
 		} else {
 			dayStr = colors.Text().Render(dayStr)
 		}
@@ -541,7 +617,7 @@ func (rs *Service) generateContinuousCalendarColumn() string {
 
 		// When we have 7 days, complete the week
 		if len(weekDays) == 7 {
-			lines = append(lines, strings.Join(weekDays, " "))
+			lines = append(lines, rs.weekNumberCell(rowDate)+strings.Join(weekDays, " "))
 			weekDays = []string{}
 		}
 
@@ -554,7 +630,7 @@ func (rs *Service) generateContinuousCalendarColumn() string {
 		for len(weekDays) < 7 {
 			weekDays = append(weekDays, "  ")
 		}
-		lines = append(lines, strings.Join(weekDays, " "))
+		lines = append(lines, rs.weekNumberCell(rowDate)+strings.Join(weekDays, " "))
 	}
 
 	return strings.Join(lines, "\n")
@@ -574,7 +650,7 @@ func (rs *Service) generateCatColumnInVerticalLayout(
 
 	for _, category := range labeledCategories {
 		for _, entry := range category.Entries {
-			_, weekNum := entry.DateStart.ISOWeek()
+			weekNum := rs.calendarWeekRow(entry.DateStart)
 			planText := colors.Text().Render(entry.String())
 			weekPlans[weekNum] = append(weekPlans[weekNum], planText)
 		}
@@ -584,9 +660,9 @@ func (rs *Service) generateCatColumnInVerticalLayout(
 
 	lines = append(lines, "") // Header line
 
-	maxWeeksInYear := 53
+	maxWeeksInPeriod := rs.calendarWeekRow(rs.period.End)
 
-	for week := 1; week <= maxWeeksInYear; week++ {
+	for week := 1; week <= maxWeeksInPeriod; week++ {
 		if plans, exists := weekPlans[week]; exists && len(plans) > 0 {
 			for _, plan := range plans {
 				lines = append(lines, plan)
@@ -599,6 +675,25 @@ func (rs *Service) generateCatColumnInVerticalLayout(
 	return strings.Join(lines, "\n")
 }
 
+// calendarWeekRow returns the 1-based week row of the date in the continuous
+// calendar column. Dates outside the period are placed on the first or last row.
+func (rs *Service) calendarWeekRow(date entity.Date) int {
+	firstDay := rs.period.Start
+	lastDay := rs.period.End
+
+	if date.Before(firstDay) {
+		date = firstDay
+	}
+	if date.After(lastDay) {
+		date = lastDay
+	}
+
+	offset := calendar.WeekdayColumn(firstDay, rs.ctx.FirstWeekday)
+	days := date.DaysSince(firstDay)
+
+	return (offset+days)/7 + 1
+}
+
 // renderLegendAndStatistics adds legend and statistics at the bottom
 func (rs *Service) renderLegendAndStatistics() {
 	maxWidth := rs.maxWidthInChars
@@ -670,8 +765,8 @@ func (rs *Service) createLeftSidePanelContent(
 ) string {
 	var content strings.Builder
 
-	for rowStart := 0; rowStart < 12; rowStart += calendarCols {
-		end := min(rowStart+calendarCols, 12)
+	for rowStart := 0; rowStart < len(allMonths); rowStart += calendarCols {
+		end := min(rowStart+calendarCols, len(allMonths))
 		s := rs.printMonthRow(allMonths[rowStart:end])
 		content.WriteString(s)
 	}
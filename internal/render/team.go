@@ -0,0 +1,193 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/list"
+	"github.com/nsr888/lifecalendar/internal/calendar"
+	"github.com/nsr888/lifecalendar/internal/entity"
+	"github.com/nsr888/lifecalendar/pkg/colors"
+)
+
+const teamCoverageLabel = "away"
+
+// RenderTeamView prints one row per person for every month of the period,
+// a coverage row with the number of people away each day, and the conflicts
+// with more people away than allowed.
+func (rs *Service) RenderTeamView(members []entity.TeamMember, conflicts []entity.TeamConflict) {
+	nameWidth := len(teamCoverageLabel)
+	for _, member := range members {
+		nameWidth = max(nameWidth, len(member.Name))
+	}
+	nameWidth = min(nameWidth, 12) + 1
+
+	coverage := calendar.TeamCoverage(members)
+	months := rs.period.Months()
+
+	for _, month := range months {
+		var days []entity.Date
+		for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+			if rs.period.Contains(day) {
+				days = append(days, day)
+			}
+		}
+
+		fmt.Println(colors.Header().Render(rs.monthTitle(month, len(months))))
+		fmt.Println(strings.Repeat(" ", nameWidth) + rs.teamDayHeader(days))
+
+		for _, member := range members {
+			fmt.Println(teamName(member.Name, nameWidth) + rs.teamMemberRow(member, days))
+		}
+
+		coverageRow := rs.teamCoverageRow(coverage, days, len(members))
+		fmt.Println(teamName(teamCoverageLabel, nameWidth) + coverageRow)
+	}
+
+	fmt.Println(rs.generateTeamSummaryLines(members, conflicts))
+}
+
+func teamName(name string, width int) string {
+	if len(name) >= width {
+		name = name[:width-1]
+	}
+
+	return colors.Text().Render(fmt.Sprintf("%-*s", width, name))
+}
+
+// teamDayHeader numbers the first day and every first weekday, so the
+// two-character day cells stay readable.
+func (rs *Service) teamDayHeader(days []entity.Date) string {
+	var header strings.Builder
+	for i, day := range days {
+		if i == 0 || calendar.WeekdayColumn(day, rs.ctx.FirstWeekday) == 0 {
+			header.WriteString(fmt.Sprintf("%2d", day.Day()))
+		} else {
+			header.WriteString("  ")
+		}
+	}
+
+	return colors.Text().Render(header.String())
+}
+
+// teamMemberRow marks full days away with a block, partial days with a half
+// block and days off of the work schedule with blanks.
+func (rs *Service) teamMemberRow(member entity.TeamMember, days []entity.Date) string {
+	awayStyle := lipgloss.NewStyle().Foreground(rs.teamAwayColor())
+	offStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#4d4d4d"))
+
+	var row strings.Builder
+	for _, day := range days {
+		fraction, away := member.Absent[day]
+		switch {
+		case away && fraction >= 1:
+			row.WriteString(awayStyle.Render("██"))
+		case away:
+			row.WriteString(awayStyle.Render("▄▄"))
+		case rs.ctx.IsWorkingDay(day):
+			row.WriteString(offStyle.Render(" ·"))
+		default:
+			row.WriteString("  ")
+		}
+	}
+
+	return row.String()
+}
+
+// teamAwayColor is the background of the vacations category, or green when
+// it has none.
+func (rs *Service) teamAwayColor() lipgloss.TerminalColor {
+	color := rs.styleService.GetCategoryStyle("vacations").GetBackground()
+	if color == (lipgloss.NoColor{}) {
+		return lipgloss.Color("#5f8f5f")
+	}
+
+	return color
+}
+
+// teamCoverageRow is the heatmap of people away per day; days above the
+// configured maximum are red.
+func (rs *Service) teamCoverageRow(
+	coverage map[entity.Date]int,
+	days []entity.Date,
+	total int,
+) string {
+	var row strings.Builder
+	for _, day := range days {
+		count := coverage[day]
+		if count == 0 {
+			row.WriteString("  ")
+			continue
+		}
+
+		style := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ffffff")).
+			Background(heatColor(count, total, rs.appConfig.Team.MaxAbsent))
+		row.WriteString(style.Render(fmt.Sprintf("%2d", count)))
+	}
+
+	return row.String()
+}
+
+func heatColor(count, total, maxAbsent int) lipgloss.Color {
+	ratio := float64(count) / float64(max(total, 1))
+
+	switch {
+	case maxAbsent > 0 && count > maxAbsent:
+		return lipgloss.Color("#cc0000")
+	case ratio <= 0.25:
+		return lipgloss.Color("#4d6b3c")
+	case ratio <= 0.5:
+		return lipgloss.Color("#8a7a2e")
+	default:
+		return lipgloss.Color("#a0522d")
+	}
+}
+
+// generateTeamSummaryLines lists the working days each person is away and
+// the conflicts.
+func (rs *Service) generateTeamSummaryLines(
+	members []entity.TeamMember,
+	conflicts []entity.TeamConflict,
+) string {
+	var lines strings.Builder
+
+	lines.WriteString(colors.Header().Render("Days away:") + "\n")
+	away := list.New().Enumerator(list.Bullet).EnumeratorStyle(colors.Text().MarginRight(1))
+	for _, member := range members {
+		var days float64
+		for _, fraction := range member.Absent {
+			days += fraction
+		}
+		away.Item(colors.Text().Render(fmt.Sprintf("%s: %s", member.Name, formatDays(days))))
+	}
+	lines.WriteString(away.String() + "\n")
+
+	maxAbsent := rs.appConfig.Team.MaxAbsent
+	if maxAbsent == 0 {
+		return lines.String()
+	}
+
+	lines.WriteString(colors.Header().Render("Conflicts:") + "\n")
+	if len(conflicts) == 0 {
+		lines.WriteString(colors.Text().Render(
+			fmt.Sprintf("Never more than %d away at once", maxAbsent),
+		) + "\n")
+		return lines.String()
+	}
+
+	warnings := list.New().Enumerator(list.Bullet).EnumeratorStyle(colors.Text().MarginRight(1))
+	for _, conflict := range conflicts {
+		warnings.Item(colors.Text().Render(fmt.Sprintf(
+			"%s-%s: up to %d away (%s)",
+			conflict.Start.Format("02.01"),
+			conflict.End.Format("02.01"),
+			conflict.MaxAbsent,
+			strings.Join(conflict.People, ", "),
+		)))
+	}
+	lines.WriteString(warnings.String() + "\n")
+
+	return lines.String()
+}
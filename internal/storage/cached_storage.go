@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+// ModTimer is implemented by storages that can tell when the data a year's
+// load depends on last changed.
+type ModTimer interface {
+	ModTime(year int) (time.Time, error)
+}
+
+// Preloader is implemented by storages that can load several years ahead.
+type Preloader interface {
+	Preload(years []int) error
+}
+
+// CachedStorage memoises the per-year loads of another storage. A cached year
+// is reloaded when the storage reports a newer modification time, and writes
+// through the cache drop the years they affect. Loaded data is cloned on
+// return, so callers may change it freely.
+type CachedStorage struct {
+	Storage
+
+	mu    sync.Mutex
+	years map[int]cachedYear
+}
+
+type cachedYear struct {
+	data    *entity.CategoryName
+	modTime time.Time
+}
+
+func NewCachedStorage(storage Storage) *CachedStorage {
+	return &CachedStorage{
+		Storage: storage,
+		years:   make(map[int]cachedYear),
+	}
+}
+
+// Unwrap returns the storage behind the cache.
+func (s *CachedStorage) Unwrap() Storage {
+	return s.Storage
+}
+
+// Unwrap returns the storage behind any caching layers, to reach optional
+// interfaces like Validator.
+func Unwrap(storage Storage) Storage {
+	for {
+		wrapper, ok := storage.(interface{ Unwrap() Storage })
+		if !ok {
+			return storage
+		}
+		storage = wrapper.Unwrap()
+	}
+}
+
+func (s *CachedStorage) LoadCategoryByYear(year int) (*entity.CategoryName, error) {
+	data, err := s.load(year)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Clone(), nil
+}
+
+func (s *CachedStorage) LoadLabeledCategories(year int) ([]LabeledCategory, error) {
+	data, err := s.load(year)
+	if err != nil {
+		return nil, err
+	}
+
+	return labeledCategories(data), nil
+}
+
+// Preload loads the years with data that are not cached yet, several at a
+// time. Years without data are skipped.
+func (s *CachedStorage) Preload(years []int) error {
+	jobs := make(chan int)
+	errs := make(chan error, len(years))
+
+	var wg sync.WaitGroup
+	for range min(len(years), runtime.GOMAXPROCS(0)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for year := range jobs {
+				if _, err := s.load(year); err != nil {
+					errs <- fmt.Errorf("failed to preload year %d: %w", year, err)
+				}
+			}
+		}()
+	}
+
+	for _, year := range years {
+		if s.IsYearDataExists(year) {
+			jobs <- year
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// load returns the cached data of the year, loading it when it is missing or
+// out of date. The result is shared and must not be changed.
+func (s *CachedStorage) load(year int) (*entity.CategoryName, error) {
+	modTime, err := s.modTime(year)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	cached, exists := s.years[year]
+	s.mu.Unlock()
+
+	if exists && cached.modTime.Equal(modTime) {
+		return cached.data, nil
+	}
+
+	data, err := s.Storage.LoadCategoryByYear(year)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.years[year] = cachedYear{data: data, modTime: modTime}
+	s.mu.Unlock()
+
+	return data, nil
+}
+
+func (s *CachedStorage) modTime(year int) (time.Time, error) {
+	modTimer, ok := s.Storage.(ModTimer)
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	modTime, err := modTimer.ModTime(year)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to check data of year %d: %w", year, err)
+	}
+
+	return modTime, nil
+}
+
+// invalidate drops the year and its neighbours, whose loads include entries
+// of the year that overlap them.
+func (s *CachedStorage) invalidate(year int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for y := year - 1; y <= year+1; y++ {
+		delete(s.years, y)
+	}
+}
+
+func (s *CachedStorage) AddEntry(year int, category string, entry entity.CategoryEntry) error {
+	defer s.invalidate(year)
+	return s.Storage.AddEntry(year, category, entry)
+}
+
+func (s *CachedStorage) UpdateEntry(
+	year int,
+	category string,
+	old, updated entity.CategoryEntry,
+) error {
+	defer s.invalidate(year)
+	return s.Storage.UpdateEntry(year, category, old, updated)
+}
+
+func (s *CachedStorage) DeleteEntry(year int, category string, entry entity.CategoryEntry) error {
+	defer s.invalidate(year)
+	return s.Storage.DeleteEntry(year, category, entry)
+}
+
+func (s *CachedStorage) CreateCategory(year int, category string) error {
+	defer s.invalidate(year)
+	return s.Storage.CreateCategory(year, category)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +21,7 @@ const (
 	dateCol      = "date"
 	labelCol     = "label"
 	descCol      = "desc"
+	portionCol   = "portion"
 )
 
 type CSVStorage struct {
@@ -39,6 +41,71 @@ func (s *CSVStorage) IsYearDataExists(year int) bool {
 	return true
 }
 
+// ListYears returns all years that have a data directory, in ascending order.
+func (s *CSVStorage) ListYears() ([]int, error) {
+	entries, err := os.ReadDir(s.dataFolder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data folder: %w", err)
+	}
+
+	var years []int
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		year, convErr := strconv.Atoi(entry.Name())
+		if convErr != nil {
+			continue
+		}
+		years = append(years, year)
+	}
+
+	sort.Ints(years)
+
+	return years, nil
+}
+
+// ModTime returns the latest modification time of the year folder, the
+// adjacent year folders and their files, which LoadCategoryByYear reads.
+func (s *CSVStorage) ModTime(year int) (time.Time, error) {
+	var latest time.Time
+
+	for adjacentYear := year - 1; adjacentYear <= year+1; adjacentYear++ {
+		dataDir := fmt.Sprintf("%s/%d", s.dataFolder, adjacentYear)
+
+		info, err := os.Stat(dataDir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to stat data directory: %w", err)
+		}
+		latest = laterTime(latest, info.ModTime())
+
+		entries, err := os.ReadDir(dataDir)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to read data directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			entryInfo, infoErr := entry.Info()
+			if infoErr != nil {
+				return time.Time{}, fmt.Errorf("failed to stat %s: %w", entry.Name(), infoErr)
+			}
+			latest = laterTime(latest, entryInfo.ModTime())
+		}
+	}
+
+	return latest, nil
+}
+
+func laterTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
 // GetCategoryNames returns all category names for a given year (excluding weekends).
 func (s *CSVStorage) GetCategoryNames(year int) ([]string, error) {
 	dataDir := fmt.Sprintf("%s/%d", s.dataFolder, year)
@@ -63,20 +130,26 @@ type LabeledCategory struct {
 }
 
 type CategoryEntry struct {
-	DateStart time.Time
-	DateEnd   time.Time
+	DateStart entity.Date
+	DateEnd   entity.Date
 	Label     string
+	Portion   entity.DayPortion
 }
 
 func (ce CategoryEntry) String() string {
 	startStr := ce.DateStart.Format("02.01")
 	endStr := ce.DateEnd.Format("02.01")
 
-	totalDays := int(ce.DateEnd.Sub(ce.DateStart).Hours()/24) + 1
+	totalDays := ce.DateEnd.DaysSince(ce.DateStart) + 1
 	var daysText string
-	if totalDays == 1 {
+	switch {
+	case ce.Portion != entity.PortionFull && totalDays == 1:
+		daysText = "½ day"
+	case ce.Portion != entity.PortionFull:
+		daysText = fmt.Sprintf("%d half days", totalDays)
+	case totalDays == 1:
 		daysText = "1 day"
-	} else {
+	default:
 		daysText = fmt.Sprintf("%d days", totalDays)
 	}
 
@@ -87,9 +160,44 @@ func (ce CategoryEntry) String() string {
 	return entryLine
 }
 
+// LoadCategoryByYear loads all categories of the year's folder together with
+// entries from adjacent year folders that overlap the year. Entries keep their
+// full span, while Dates only contain the days inside the year.
 func (s *CSVStorage) LoadCategoryByYear(
 	year int,
 ) (*entity.CategoryName, error) {
+	categories, err := s.loadYearFolder(year)
+	if err != nil {
+		return nil, &DataError{Err: err}
+	}
+
+	for _, adjacentYear := range []int{year - 1, year + 1} {
+		if !s.IsYearDataExists(adjacentYear) {
+			continue
+		}
+
+		adjacent, loadErr := s.loadYearFolder(adjacentYear)
+		if loadErr != nil {
+			return nil, &DataError{Err: fmt.Errorf(
+				"failed to load adjacent year %d: %w",
+				adjacentYear,
+				loadErr,
+			)}
+		}
+
+		mergeOverlappingEntries(categories, adjacent, year, adjacentYear < year)
+	}
+
+	clipDatesToYear(categories, year)
+
+	return &entity.CategoryName{
+		BaseYear:   year,
+		Categories: categories,
+	}, nil
+}
+
+// loadYearFolder loads every category file of a single year folder as is.
+func (s *CSVStorage) loadYearFolder(year int) (map[string]*entity.Category, error) {
 	dataDir := fmt.Sprintf("%s/%d", s.dataFolder, year)
 
 	categoryFiles, err := s.getCategoryToFileMap(dataDir)
@@ -114,10 +222,7 @@ func (s *CSVStorage) LoadCategoryByYear(
 		categories[categoryName] = category
 	}
 
-	return &entity.CategoryName{
-		BaseYear:   year,
-		Categories: categories,
-	}, nil
+	return categories, nil
 }
 
 // getCategoryToFileMap scans the data directory for CSV files and returns a map of category name -> filename.
@@ -160,12 +265,7 @@ func (s *CSVStorage) loadCategoryFromFile(
 		return nil, err
 	}
 
-	category := &entity.Category{
-		Type:    categoryType,
-		Desc:    string(categoryType),
-		Dates:   make(map[time.Time]struct{}),
-		Entries: []entity.CategoryEntry{},
-	}
+	category := newCategory(categoryType)
 
 	if len(records) == 0 {
 		return category, nil
@@ -191,21 +291,101 @@ func (s *CSVStorage) loadCategoryFromFile(
 			)
 		}
 
-		category.Entries = append(category.Entries, entry)
+		appendEntry(category, entry)
+	}
+
+	return category, nil
+}
+
+// newCategory creates an empty category of the given type.
+func newCategory(categoryType entity.CategoryType) *entity.Category {
+	return &entity.Category{
+		Type:    categoryType,
+		Desc:    string(categoryType),
+		Dates:   make(map[entity.Date]struct{}),
+		Entries: []entity.CategoryEntry{},
+	}
+}
+
+// yearBounds returns the first and the last day of the year.
+func yearBounds(year int) (entity.Date, entity.Date) {
+	return entity.NewDate(year, time.January, 1),
+		entity.NewDate(year, time.December, 31)
+}
+
+// mergeOverlappingEntries adds entries of an adjacent year that overlap year
+// to categories. Entries of earlier years go before the year's own entries.
+func mergeOverlappingEntries(
+	categories, adjacent map[string]*entity.Category,
+	year int,
+	earlier bool,
+) {
+	yearStart, yearEnd := yearBounds(year)
+
+	for categoryName, adjacentCategory := range adjacent {
+		var overlapping []entity.CategoryEntry
+		for _, entry := range adjacentCategory.Entries {
+			if entry.Overlaps(yearStart, yearEnd) {
+				overlapping = append(overlapping, entry)
+			}
+		}
+
+		if len(overlapping) == 0 {
+			continue
+		}
+
+		category, exists := categories[categoryName]
+		if !exists {
+			category = newCategory(adjacentCategory.Type)
+			categories[categoryName] = category
+		}
+
+		if earlier {
+			ownEntries := category.Entries
+			category.Entries = nil
+			for _, entry := range overlapping {
+				appendEntry(category, entry)
+			}
+			category.Entries = append(category.Entries, ownEntries...)
+			continue
+		}
 
-		for cur := entry.DateStart; !cur.After(entry.DateEnd); cur = cur.AddDate(0, 0, 1) {
-			category.Dates[cur] = struct{}{}
+		for _, entry := range overlapping {
+			appendEntry(category, entry)
 		}
 	}
+}
 
-	return category, nil
+// clipDatesToYear drops all days outside the year from the categories' Dates.
+func clipDatesToYear(categories map[string]*entity.Category, year int) {
+	for _, category := range categories {
+		for date := range category.Dates {
+			if date.Year() != year {
+				delete(category.Dates, date)
+				delete(category.Portions, date)
+			}
+		}
+	}
+}
+
+// appendEntry adds an entry to the category and marks all its days.
+func appendEntry(category *entity.Category, entry entity.CategoryEntry) {
+	category.Entries = append(category.Entries, entry)
+
+	for cur := entry.DateStart; !cur.After(entry.DateEnd); cur = cur.AddDate(0, 0, 1) {
+		category.AddDay(cur, entry.Portion)
+	}
 }
 
-// createHeaderMap creates a mapping from normalized header names to column indices.
+// createHeaderMap creates a mapping from normalized header names to column
+// indices. Of duplicate columns the first one is used, as validate reports.
 func (s *CSVStorage) createHeaderMap(headers []string) map[string]int {
 	headerMap := make(map[string]int)
 	for i, header := range headers {
-		headerMap[strings.ToLower(strings.TrimSpace(header))] = i
+		name := strings.ToLower(strings.TrimSpace(header))
+		if _, duplicate := headerMap[name]; !duplicate {
+			headerMap[name] = i
+		}
 	}
 	return headerMap
 }
@@ -215,21 +395,21 @@ func (s *CSVStorage) parseDateField(
 	record []string,
 	headerMap map[string]int,
 	fieldName string,
-) (time.Time, bool) {
+) (entity.Date, bool) {
 	if idx, exists := headerMap[fieldName]; exists && idx < len(record) {
-		if date, err := time.ParseInLocation(dateLayout, strings.TrimSpace(record[idx]), time.Local); err == nil {
+		if date, err := entity.ParseDate(strings.TrimSpace(record[idx])); err == nil {
 			return date, true
 		}
 	}
-	return time.Time{}, false
+	return entity.Date{}, false
 }
 
 // parseDates parses start and end dates from a CSV record.
 func (s *CSVStorage) parseDates(
 	record []string,
 	headerMap map[string]int,
-) (time.Time, time.Time) {
-	var startDate, endDate time.Time
+) (entity.Date, entity.Date) {
+	var startDate, endDate entity.Date
 
 	if date, ok := s.parseDateField(record, headerMap, dateStartCol); ok {
 		startDate = date
@@ -264,6 +444,17 @@ func (s *CSVStorage) parseLabel(
 	return ""
 }
 
+// parsePortion parses the optional portion column of a CSV record.
+func (s *CSVStorage) parsePortion(
+	record []string,
+	headerMap map[string]int,
+) (entity.DayPortion, error) {
+	if idx, exists := headerMap[portionCol]; exists && idx < len(record) {
+		return entity.ParseDayPortion(record[idx])
+	}
+	return entity.PortionFull, nil
+}
+
 func (s *CSVStorage) parseCSVRecord(
 	record, headers []string,
 ) (entity.CategoryEntry, error) {
@@ -273,10 +464,20 @@ func (s *CSVStorage) parseCSVRecord(
 	entry.DateStart, entry.DateEnd = s.parseDates(record, headerMap)
 	entry.Label = s.parseLabel(record, headerMap)
 
+	portion, err := s.parsePortion(record, headerMap)
+	if err != nil {
+		return entity.CategoryEntry{}, err
+	}
+	entry.Portion = portion
+
 	if entry.DateStart.IsZero() || entry.DateEnd.IsZero() {
 		return entity.CategoryEntry{}, errors.New("invalid date range")
 	}
 
+	if entry.DateEnd.Before(entry.DateStart) {
+		return entity.CategoryEntry{}, errors.New("date_end is before date_start")
+	}
+
 	if entry.Label == "" {
 		entry.Label = "Event"
 	}
@@ -292,6 +493,11 @@ func (s *CSVStorage) LoadLabeledCategories(
 		return nil, err
 	}
 
+	return labeledCategories(data), nil
+}
+
+// labeledCategories collects the labeled entries of every category, sorted by name.
+func labeledCategories(data *entity.CategoryName) []LabeledCategory {
 	var labeledCategories []LabeledCategory
 
 	for categoryName, category := range data.Categories {
@@ -303,6 +509,7 @@ func (s *CSVStorage) LoadLabeledCategories(
 					DateStart: entry.DateStart,
 					DateEnd:   entry.DateEnd,
 					Label:     entry.Label,
+					Portion:   entry.Portion,
 				})
 			}
 		}
@@ -322,5 +529,5 @@ func (s *CSVStorage) LoadLabeledCategories(
 		return labeledCategories[i].Name < labeledCategories[j].Name
 	})
 
-	return labeledCategories, nil
+	return labeledCategories
 }
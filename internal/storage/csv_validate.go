@@ -0,0 +1,379 @@
+package storage
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic describes a problem found in the data at a file position.
+type Diagnostic struct {
+	File     string
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Line, d.Column, d.Severity, d.Message)
+}
+
+// Validator is implemented by storages that can check their data in place.
+type Validator interface {
+	Validate(year int) ([]Diagnostic, error)
+}
+
+// knownColumns lists the header names understood by the CSV loader.
+var knownColumns = map[string]struct{}{
+	dateStartCol: {},
+	dateEndCol:   {},
+	dateCol:      {},
+	labelCol:     {},
+	descCol:      {},
+	portionCol:   {},
+}
+
+// validatedRow is a successfully parsed row kept for duplicate and overlap checks.
+type validatedRow struct {
+	line      int
+	column    int
+	dateStart entity.Date
+	dateEnd   entity.Date
+	label     string
+	portion   entity.DayPortion
+}
+
+// Validate checks every category file of the year folder and reports
+// malformed rows, reversed ranges, overlaps and entries outside the year.
+func (s *CSVStorage) Validate(year int) ([]Diagnostic, error) {
+	dataDir := fmt.Sprintf("%s/%d", s.dataFolder, year)
+	if !s.IsYearDataExists(year) {
+		return []Diagnostic{{
+			File:     dataDir,
+			Line:     1,
+			Column:   1,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("data folder for year %d does not exist", year),
+		}}, nil
+	}
+
+	categoryFiles, err := s.getCategoryToFileMap(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover category files: %w", err)
+	}
+
+	filenames := make([]string, 0, len(categoryFiles))
+	for _, filename := range categoryFiles {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	var diagnostics []Diagnostic
+	for _, filename := range filenames {
+		fileDiagnostics, validateErr := s.validateCategoryFile(filename, year)
+		if validateErr != nil {
+			return nil, fmt.Errorf("failed to validate %s: %w", filename, validateErr)
+		}
+		diagnostics = append(diagnostics, fileDiagnostics...)
+	}
+
+	return diagnostics, nil
+}
+
+func (s *CSVStorage) validateCategoryFile(filename string, year int) ([]Diagnostic, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	report := func(
+		line, column int,
+		severity Severity,
+		format string,
+		args ...any,
+	) Diagnostic {
+		return Diagnostic{
+			File:     filename,
+			Line:     line,
+			Column:   column,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		}
+	}
+
+	headers, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return []Diagnostic{csvErrorDiagnostic(filename, err)}, nil
+	}
+
+	diagnostics, headerMap := validateHeaders(reader, headers, report)
+
+	var rows []validatedRow
+	for {
+		record, readErr := reader.Read()
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			diagnostics = append(diagnostics, csvErrorDiagnostic(filename, readErr))
+			break
+		}
+		if len(record) == 0 || record[0] == "" {
+			continue
+		}
+
+		row, rowDiagnostics := s.validateRecord(reader, record, headers, headerMap, year, report)
+		diagnostics = append(diagnostics, rowDiagnostics...)
+		if row != nil {
+			rows = append(rows, *row)
+		}
+	}
+
+	diagnostics = append(diagnostics, findOverlaps(rows, report)...)
+
+	return diagnostics, nil
+}
+
+type reportFunc func(line, column int, severity Severity, format string, args ...any) Diagnostic
+
+func validateHeaders(
+	reader *csv.Reader,
+	headers []string,
+	report reportFunc,
+) ([]Diagnostic, map[string]int) {
+	var diagnostics []Diagnostic
+	headerMap := make(map[string]int)
+
+	for i, header := range headers {
+		name := strings.ToLower(strings.TrimSpace(header))
+		line, column := reader.FieldPos(i)
+
+		if _, known := knownColumns[name]; !known {
+			diagnostics = append(diagnostics, report(
+				line, column, SeverityWarning, "unknown column %q is ignored", header,
+			))
+			continue
+		}
+		if _, duplicate := headerMap[name]; duplicate {
+			diagnostics = append(diagnostics, report(
+				line, column, SeverityWarning, "duplicate column %q is ignored", header,
+			))
+			continue
+		}
+		headerMap[name] = i
+	}
+
+	_, hasStart := headerMap[dateStartCol]
+	_, hasDate := headerMap[dateCol]
+	if !hasStart && !hasDate {
+		line, _ := reader.FieldPos(0)
+		diagnostics = append(diagnostics, report(
+			line, 1, SeverityError,
+			"header needs a %q or %q column", dateStartCol, dateCol,
+		))
+	}
+
+	return diagnostics, headerMap
+}
+
+// validateRecord checks a single row and returns it when its dates are usable.
+func (s *CSVStorage) validateRecord(
+	reader *csv.Reader,
+	record, headers []string,
+	headerMap map[string]int,
+	year int,
+	report reportFunc,
+) (*validatedRow, []Diagnostic) {
+	var diagnostics []Diagnostic
+	line, _ := reader.FieldPos(0)
+
+	if len(record) > len(headers) {
+		_, column := reader.FieldPos(len(headers))
+		diagnostics = append(diagnostics, report(
+			line, column, SeverityWarning,
+			"%d extra field(s) without a header are ignored", len(record)-len(headers),
+		))
+	}
+
+	invalid := false
+	parse := func(columnName string) (entity.Date, int, bool) {
+		idx, exists := headerMap[columnName]
+		if !exists || idx >= len(record) {
+			return entity.Date{}, 0, false
+		}
+
+		_, column := reader.FieldPos(idx)
+		value := strings.TrimSpace(record[idx])
+		if value == "" {
+			return entity.Date{}, column, false
+		}
+
+		date, err := entity.ParseDate(value)
+		if err != nil {
+			diagnostics = append(diagnostics, report(
+				line, column, SeverityError,
+				"invalid %s %q: expected YYYY-MM-DD", columnName, value,
+			))
+			invalid = true
+			return entity.Date{}, column, false
+		}
+
+		return date, column, true
+	}
+
+	dateStart, startColumn, hasStart := parse(dateStartCol)
+	dateEnd, endColumn, hasEnd := parse(dateEndCol)
+	if !hasStart {
+		dateStart, startColumn, hasStart = parse(dateCol)
+	}
+	if hasStart && !hasEnd {
+		dateEnd, endColumn, hasEnd = dateStart, startColumn, true
+	}
+
+	if invalid {
+		return nil, diagnostics
+	}
+	if !hasStart || !hasEnd {
+		diagnostics = append(diagnostics, report(
+			line, 1, SeverityError, "row has no start date",
+		))
+		return nil, diagnostics
+	}
+
+	if dateEnd.Before(dateStart) {
+		diagnostics = append(diagnostics, report(
+			line, endColumn, SeverityError, "date_end %s is before date_start %s",
+			dateEnd.Format(dateLayout), dateStart.Format(dateLayout),
+		))
+		return nil, diagnostics
+	}
+
+	portion, err := s.parsePortion(record, headerMap)
+	if err != nil {
+		_, column := reader.FieldPos(headerMap[portionCol])
+		diagnostics = append(diagnostics, report(line, column, SeverityError, "%v", err))
+		return nil, diagnostics
+	}
+
+	yearStart, yearEnd := yearBounds(year)
+	if dateEnd.Before(yearStart) || dateStart.After(yearEnd) {
+		diagnostics = append(diagnostics, report(
+			line, startColumn, SeverityError,
+			"entry %s..%s lies outside its year folder %d",
+			dateStart.Format(dateLayout), dateEnd.Format(dateLayout), year,
+		))
+	}
+
+	return &validatedRow{
+		line:      line,
+		column:    startColumn,
+		dateStart: dateStart,
+		dateEnd:   dateEnd,
+		label:     s.parseLabel(record, headerMap),
+		portion:   portion,
+	}, diagnostics
+}
+
+// findOverlaps reports duplicate rows and rows whose ranges overlap an earlier row.
+func findOverlaps(rows []validatedRow, report reportFunc) []Diagnostic {
+	sorted := make([]validatedRow, len(rows))
+	copy(sorted, rows)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].dateStart.Before(sorted[j].dateStart)
+	})
+
+	var diagnostics []Diagnostic
+	for i, row := range sorted {
+		for _, earlier := range sorted[:i] {
+			if earlier.dateEnd.Before(row.dateStart) {
+				continue
+			}
+
+			if !earlier.entry().Conflicts(row.entry()) {
+				continue
+			}
+
+			first, second := earlier, row
+			if second.line < first.line {
+				first, second = second, first
+			}
+
+			if first.dateStart.Equal(second.dateStart) &&
+				first.dateEnd.Equal(second.dateEnd) &&
+				first.label == second.label &&
+				first.portion == second.portion {
+				diagnostics = append(diagnostics, report(
+					second.line, second.column, SeverityError,
+					"duplicate of line %d", first.line,
+				))
+				continue
+			}
+
+			diagnostics = append(diagnostics, report(
+				second.line, second.column, SeverityError,
+				"range %s..%s overlaps line %d (%s..%s)",
+				second.dateStart.Format(dateLayout), second.dateEnd.Format(dateLayout),
+				first.line,
+				first.dateStart.Format(dateLayout), first.dateEnd.Format(dateLayout),
+			))
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Line < diagnostics[j].Line
+	})
+
+	return diagnostics
+}
+
+func csvErrorDiagnostic(filename string, err error) Diagnostic {
+	diagnostic := Diagnostic{
+		File:     filename,
+		Line:     1,
+		Column:   1,
+		Severity: SeverityError,
+		Message:  err.Error(),
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		diagnostic.Line = parseErr.Line
+		diagnostic.Column = parseErr.Column
+		diagnostic.Message = parseErr.Err.Error()
+	}
+
+	return diagnostic
+}
+
+var _ Validator = (*CSVStorage)(nil)
+
+// entry returns the dates and portion of the row as an entry.
+func (r validatedRow) entry() entity.CategoryEntry {
+	return entity.CategoryEntry{
+		DateStart: r.dateStart,
+		DateEnd:   r.dateEnd,
+		Label:     r.label,
+		Portion:   r.portion,
+	}
+}
@@ -0,0 +1,327 @@
+package storage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nsr888/lifecalendar/internal/entity"
+)
+
+// defaultHeaders is the header layout used for newly created category files.
+var defaultHeaders = []string{dateStartCol, dateEndCol, labelCol}
+
+func (s *CSVStorage) CreateCategory(year int, category string) error {
+	filename, err := s.categoryFilename(year, category)
+	if err != nil {
+		return err
+	}
+
+	if _, statErr := os.Stat(filename); statErr == nil {
+		return fmt.Errorf("%w: %s", ErrCategoryExists, category)
+	}
+
+	if mkdirErr := os.MkdirAll(filepath.Dir(filename), 0o755); mkdirErr != nil {
+		return fmt.Errorf("failed to create year directory: %w", mkdirErr)
+	}
+
+	return writeRecordsAtomic(filename, [][]string{defaultHeaders})
+}
+
+func (s *CSVStorage) AddEntry(
+	year int,
+	category string,
+	entry entity.CategoryEntry,
+) error {
+	if err := validateEntry(entry); err != nil {
+		return err
+	}
+
+	filename, err := s.categoryFilename(year, category)
+	if err != nil {
+		return err
+	}
+
+	if _, statErr := os.Stat(filename); os.IsNotExist(statErr) {
+		if createErr := s.CreateCategory(year, category); createErr != nil {
+			return createErr
+		}
+	}
+
+	records, err := readRecords(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read category %s: %w", category, err)
+	}
+
+	if entry.Label != "" {
+		records = ensureColumn(records, labelCol, descCol)
+	}
+	if entry.Portion != entity.PortionFull {
+		records = ensureColumn(records, portionCol)
+	}
+
+	record, err := formatRecord(records[0], entry)
+	if err != nil {
+		return err
+	}
+	records = append(records, record)
+
+	return writeRecordsAtomic(filename, records)
+}
+
+func (s *CSVStorage) UpdateEntry(
+	year int,
+	category string,
+	old, updated entity.CategoryEntry,
+) error {
+	if err := validateEntry(updated); err != nil {
+		return err
+	}
+
+	replace := func(records [][]string, idx int) ([][]string, error) {
+		if updated.Label != "" {
+			records = ensureColumn(records, labelCol, descCol)
+		}
+		if updated.Portion != entity.PortionFull {
+			records = ensureColumn(records, portionCol)
+		}
+
+		record, err := formatRecord(records[0], updated)
+		if err != nil {
+			return nil, err
+		}
+		records[idx] = record
+
+		return records, nil
+	}
+
+	return s.rewriteEntry(year, category, old, replace)
+}
+
+func (s *CSVStorage) DeleteEntry(
+	year int,
+	category string,
+	entry entity.CategoryEntry,
+) error {
+	remove := func(records [][]string, idx int) ([][]string, error) {
+		return append(records[:idx], records[idx+1:]...), nil
+	}
+
+	return s.rewriteEntry(year, category, entry, remove)
+}
+
+// rewriteEntry finds the record matching entry and rewrites the file with
+// the records returned by modify.
+func (s *CSVStorage) rewriteEntry(
+	year int,
+	category string,
+	entry entity.CategoryEntry,
+	modify func(records [][]string, idx int) ([][]string, error),
+) error {
+	filename, err := s.categoryFilename(year, category)
+	if err != nil {
+		return err
+	}
+
+	records, err := readRecords(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read category %s: %w", category, err)
+	}
+
+	idx := s.findRecord(records, entry)
+	if idx < 0 {
+		return fmt.Errorf("%w: %s in %s/%d", ErrEntryNotFound, entry.Label, category, year)
+	}
+
+	records, err = modify(records, idx)
+	if err != nil {
+		return err
+	}
+
+	return writeRecordsAtomic(filename, records)
+}
+
+// findRecord returns the index of the first record equal to entry or -1.
+func (s *CSVStorage) findRecord(records [][]string, entry entity.CategoryEntry) int {
+	if len(records) == 0 {
+		return -1
+	}
+
+	headers := records[0]
+	for i := 1; i < len(records); i++ {
+		record := records[i]
+		if len(record) == 0 || record[0] == "" {
+			continue
+		}
+
+		parsed, err := s.parseCSVRecord(record, headers)
+		if err != nil {
+			continue
+		}
+
+		if sameEntry(parsed, entry) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (s *CSVStorage) categoryFilename(year int, category string) (string, error) {
+	if category == "" || category != filepath.Base(category) ||
+		strings.HasPrefix(category, ".") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCategoryName, category)
+	}
+
+	return filepath.Join(s.dataFolder, strconv.Itoa(year), category+".csv"), nil
+}
+
+func validateEntry(entry entity.CategoryEntry) error {
+	if entry.DateStart.IsZero() || entry.DateEnd.IsZero() {
+		return fmt.Errorf("%w: missing date", ErrInvalidEntry)
+	}
+	if entry.DateEnd.Before(entry.DateStart) {
+		return fmt.Errorf("%w: date_end before date_start", ErrInvalidEntry)
+	}
+	if _, err := entity.ParseDayPortion(string(entry.Portion)); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEntry, err)
+	}
+
+	return nil
+}
+
+// sameEntry compares the entries field by field. An empty label equals the
+// "Event" placeholder unlabeled rows are loaded with.
+func sameEntry(a, b entity.CategoryEntry) bool {
+	return a.DateStart.Equal(b.DateStart) &&
+		a.DateEnd.Equal(b.DateEnd) &&
+		normalizeLabel(a.Label) == normalizeLabel(b.Label) &&
+		a.Portion == b.Portion
+}
+
+func normalizeLabel(label string) string {
+	if label == "" {
+		return "Event"
+	}
+
+	return label
+}
+
+func readRecords(filename string) ([][]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 || len(records[0]) == 0 {
+		records = [][]string{defaultHeaders}
+	}
+
+	return records, nil
+}
+
+// ensureColumn appends column to the header unless one of the accepted
+// column names is already present, padding existing rows accordingly.
+func ensureColumn(records [][]string, column string, accepted ...string) [][]string {
+	for _, header := range records[0] {
+		name := strings.ToLower(strings.TrimSpace(header))
+		if name == column {
+			return records
+		}
+		for _, alt := range accepted {
+			if name == alt {
+				return records
+			}
+		}
+	}
+
+	records[0] = append(records[0], column)
+	for i := 1; i < len(records); i++ {
+		for len(records[i]) < len(records[0]) {
+			records[i] = append(records[i], "")
+		}
+	}
+
+	return records
+}
+
+// formatRecord renders an entry as a CSV record matching the header layout.
+func formatRecord(headers []string, entry entity.CategoryEntry) ([]string, error) {
+	record := make([]string, len(headers))
+	start := entry.DateStart.Format(dateLayout)
+	end := entry.DateEnd.Format(dateLayout)
+
+	hasEnd := false
+	for i, header := range headers {
+		switch strings.ToLower(strings.TrimSpace(header)) {
+		case dateStartCol:
+			record[i] = start
+		case dateEndCol:
+			record[i] = end
+			hasEnd = true
+		case dateCol:
+			record[i] = start
+		case labelCol, descCol:
+			record[i] = entry.Label
+		case portionCol:
+			record[i] = string(entry.Portion)
+		}
+	}
+
+	if !hasEnd && start != end {
+		return nil, fmt.Errorf(
+			"%w: category file only supports single dates",
+			ErrInvalidEntry,
+		)
+	}
+
+	return record, nil
+}
+
+// writeRecordsAtomic writes records to a temporary file next to filename and
+// renames it into place, so readers never observe a partially written file.
+func writeRecordsAtomic(filename string, records [][]string) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	writer := csv.NewWriter(tmp)
+	if err = writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write records: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+var _ Storage = (*CSVStorage)(nil)
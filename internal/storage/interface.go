@@ -1,12 +1,42 @@
 package storage
 
 import (
+	"errors"
+
 	"github.com/nsr888/lifecalendar/internal/entity"
 )
 
+var (
+	ErrEntryNotFound       = errors.New("entry not found")
+	ErrCategoryExists      = errors.New("category already exists")
+	ErrInvalidCategoryName = errors.New("invalid category name")
+	ErrInvalidEntry        = errors.New("invalid entry")
+)
+
+// DataError marks a failure caused by the stored data, e.g. a missing year
+// or a malformed row, rather than by the config or the command.
+type DataError struct {
+	Err error
+}
+
+func (e *DataError) Error() string { return e.Err.Error() }
+func (e *DataError) Unwrap() error { return e.Err }
+
 type Storage interface {
 	IsYearDataExists(year int) bool
+	// ListYears returns all years with data, in ascending order.
+	ListYears() ([]int, error)
 	GetCategoryNames(year int) ([]string, error)
 	LoadCategoryByYear(year int) (*entity.CategoryName, error)
 	LoadLabeledCategories(year int) ([]LabeledCategory, error)
+
+	// AddEntry appends an entry to the category of the given year, creating
+	// the category if it does not exist yet.
+	AddEntry(year int, category string, entry entity.CategoryEntry) error
+	// UpdateEntry replaces the first entry equal to old with updated.
+	UpdateEntry(year int, category string, old, updated entity.CategoryEntry) error
+	// DeleteEntry removes the first entry equal to entry.
+	DeleteEntry(year int, category string, entry entity.CategoryEntry) error
+	// CreateCategory creates an empty category for the given year.
+	CreateCategory(year int, category string) error
 }
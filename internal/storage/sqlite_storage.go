@@ -0,0 +1,520 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nsr888/lifecalendar/internal/entity"
+
+	_ "modernc.org/sqlite" // registers the pure-Go "sqlite" driver
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS years (
+	year INTEGER PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+	id   INTEGER PRIMARY KEY,
+	year INTEGER NOT NULL REFERENCES years(year) ON DELETE CASCADE,
+	name TEXT    NOT NULL,
+	UNIQUE (year, name)
+);
+
+CREATE TABLE IF NOT EXISTS entries (
+	id          INTEGER PRIMARY KEY,
+	category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+	date_start  TEXT    NOT NULL,
+	date_end    TEXT    NOT NULL,
+	label       TEXT    NOT NULL DEFAULT '',
+	portion     TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS entries_category_idx ON entries (category_id, date_start);
+`
+
+// SQLiteStorage keeps categories, entries and years in a single SQLite database.
+type SQLiteStorage struct {
+	db   *sql.DB
+	path string
+}
+
+func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	if err = upgradeSQLiteSchema(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to upgrade schema: %w", err)
+	}
+
+	return &SQLiteStorage{db: db, path: path}, nil
+}
+
+// upgradeSQLiteSchema adds columns introduced after a database was created.
+func upgradeSQLiteSchema(db *sql.DB) error {
+	var hasPortion bool
+	err := db.QueryRow(
+		`SELECT COUNT(*) > 0 FROM pragma_table_info('entries') WHERE name = 'portion'`,
+	).Scan(&hasPortion)
+	if err != nil {
+		return err
+	}
+
+	if !hasPortion {
+		_, err = db.Exec(`ALTER TABLE entries ADD COLUMN portion TEXT NOT NULL DEFAULT ''`)
+	}
+
+	return err
+}
+
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// ModTime returns the modification time of the database file, or of its
+// write-ahead log when that is newer. Any write changes every year.
+func (s *SQLiteStorage) ModTime(int) (time.Time, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat database: %w", err)
+	}
+
+	latest := info.ModTime()
+	if walInfo, walErr := os.Stat(s.path + "-wal"); walErr == nil {
+		latest = laterTime(latest, walInfo.ModTime())
+	}
+
+	return latest, nil
+}
+
+func (s *SQLiteStorage) IsYearDataExists(year int) bool {
+	var exists int
+	err := s.db.QueryRow(`SELECT 1 FROM years WHERE year = ?`, year).Scan(&exists)
+
+	return err == nil
+}
+
+// ListYears returns all years stored in the database, in ascending order.
+func (s *SQLiteStorage) ListYears() ([]int, error) {
+	rows, err := s.db.Query(`SELECT year FROM years ORDER BY year`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query years: %w", err)
+	}
+	defer rows.Close()
+
+	var years []int
+	for rows.Next() {
+		var year int
+		if err = rows.Scan(&year); err != nil {
+			return nil, fmt.Errorf("failed to scan year: %w", err)
+		}
+		years = append(years, year)
+	}
+
+	return years, rows.Err()
+}
+
+func (s *SQLiteStorage) GetCategoryNames(year int) ([]string, error) {
+	rows, err := s.db.Query(`SELECT name FROM categories WHERE year = ? ORDER BY name`, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categoryNames []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categoryNames = append(categoryNames, name)
+	}
+
+	return categoryNames, rows.Err()
+}
+
+func (s *SQLiteStorage) LoadCategoryByYear(
+	year int,
+) (*entity.CategoryName, error) {
+	categoryNames, err := s.GetCategoryNames(year)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[string]*entity.Category)
+	for _, categoryName := range categoryNames {
+		categories[categoryName] = newCategory(entity.CategoryType(categoryName))
+	}
+
+	// Besides the year's own entries, pick up entries stored under an
+	// adjacent year that overlap this one, mirroring CSVStorage.
+	yearStart, yearEnd := yearBounds(year)
+	rows, err := s.db.Query(`
+		SELECT c.name, e.date_start, e.date_end, e.label, e.portion
+		FROM entries e
+		JOIN categories c ON c.id = e.category_id
+		WHERE c.year = ?
+			OR (c.year IN (?, ?) AND e.date_start <= ? AND e.date_end >= ?)
+		ORDER BY c.year, e.id`,
+		year,
+		year-1,
+		year+1,
+		yearEnd.Format(dateLayout),
+		yearStart.Format(dateLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var categoryName, start, end, label, portion string
+		if err = rows.Scan(&categoryName, &start, &end, &label, &portion); err != nil {
+			return nil, fmt.Errorf("failed to scan entry: %w", err)
+		}
+
+		entry, parseErr := parseSQLiteEntry(start, end, label, portion)
+		if parseErr != nil {
+			return nil, &DataError{
+				Err: fmt.Errorf("failed to load category %s: %w", categoryName, parseErr),
+			}
+		}
+
+		category, exists := categories[categoryName]
+		if !exists {
+			category = newCategory(entity.CategoryType(categoryName))
+			categories[categoryName] = category
+		}
+		appendEntry(category, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	clipDatesToYear(categories, year)
+
+	return &entity.CategoryName{
+		BaseYear:   year,
+		Categories: categories,
+	}, nil
+}
+
+func (s *SQLiteStorage) LoadLabeledCategories(
+	year int,
+) ([]LabeledCategory, error) {
+	data, err := s.LoadCategoryByYear(year)
+	if err != nil {
+		return nil, err
+	}
+
+	return labeledCategories(data), nil
+}
+
+func (s *SQLiteStorage) CreateCategory(year int, category string) error {
+	if category == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidCategoryName, category)
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		var exists int
+		err := tx.QueryRow(
+			`SELECT 1 FROM categories WHERE year = ? AND name = ?`,
+			year,
+			category,
+		).Scan(&exists)
+		if err == nil {
+			return fmt.Errorf("%w: %s", ErrCategoryExists, category)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to query category: %w", err)
+		}
+
+		_, err = ensureSQLiteCategory(tx, year, category)
+		return err
+	})
+}
+
+func (s *SQLiteStorage) AddEntry(
+	year int,
+	category string,
+	entry entity.CategoryEntry,
+) error {
+	if err := validateEntry(entry); err != nil {
+		return err
+	}
+	if category == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidCategoryName, category)
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		return insertSQLiteEntry(tx, year, category, entry)
+	})
+}
+
+func (s *SQLiteStorage) UpdateEntry(
+	year int,
+	category string,
+	old, updated entity.CategoryEntry,
+) error {
+	if err := validateEntry(updated); err != nil {
+		return err
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		id, err := findSQLiteEntry(tx, year, category, old)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			`UPDATE entries SET date_start = ?, date_end = ?, label = ?, portion = ? WHERE id = ?`,
+			updated.DateStart.Format(dateLayout),
+			updated.DateEnd.Format(dateLayout),
+			updated.Label,
+			string(updated.Portion),
+			id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update entry: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLiteStorage) DeleteEntry(
+	year int,
+	category string,
+	entry entity.CategoryEntry,
+) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		id, err := findSQLiteEntry(tx, year, category, entry)
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(`DELETE FROM entries WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete entry: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// inTx runs fn inside a transaction, committing on success.
+func (s *SQLiteStorage) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ensureSQLiteCategory returns the id of the category, creating it and its year if needed.
+func ensureSQLiteCategory(tx *sql.Tx, year int, category string) (int64, error) {
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO years (year) VALUES (?)`, year); err != nil {
+		return 0, fmt.Errorf("failed to insert year: %w", err)
+	}
+
+	_, err := tx.Exec(
+		`INSERT OR IGNORE INTO categories (year, name) VALUES (?, ?)`,
+		year,
+		category,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert category: %w", err)
+	}
+
+	var id int64
+	err = tx.QueryRow(
+		`SELECT id FROM categories WHERE year = ? AND name = ?`,
+		year,
+		category,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query category: %w", err)
+	}
+
+	return id, nil
+}
+
+func insertSQLiteEntry(
+	tx *sql.Tx,
+	year int,
+	category string,
+	entry entity.CategoryEntry,
+) error {
+	categoryID, err := ensureSQLiteCategory(tx, year, category)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO entries (category_id, date_start, date_end, label, portion)
+		VALUES (?, ?, ?, ?, ?)`,
+		categoryID,
+		entry.DateStart.Format(dateLayout),
+		entry.DateEnd.Format(dateLayout),
+		entry.Label,
+		string(entry.Portion),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert entry: %w", err)
+	}
+
+	return nil
+}
+
+// findSQLiteEntry returns the id of the first entry equal to entry. Stored
+// empty labels match the "Event" placeholder used when loading.
+func findSQLiteEntry(
+	tx *sql.Tx,
+	year int,
+	category string,
+	entry entity.CategoryEntry,
+) (int64, error) {
+	var id int64
+	err := tx.QueryRow(`
+		SELECT e.id
+		FROM entries e
+		JOIN categories c ON c.id = e.category_id
+		WHERE c.year = ? AND c.name = ?
+			AND e.date_start = ? AND e.date_end = ?
+			AND (e.label = ? OR (? = 'Event' AND e.label = ''))
+			AND e.portion = ?
+		ORDER BY e.id
+		LIMIT 1`,
+		year,
+		category,
+		entry.DateStart.Format(dateLayout),
+		entry.DateEnd.Format(dateLayout),
+		entry.Label,
+		entry.Label,
+		string(entry.Portion),
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s in %s/%d", ErrEntryNotFound, entry.Label, category, year)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query entry: %w", err)
+	}
+
+	return id, nil
+}
+
+func parseSQLiteEntry(start, end, label, portion string) (entity.CategoryEntry, error) {
+	dateStart, err := entity.ParseDate(start)
+	if err != nil {
+		return entity.CategoryEntry{}, fmt.Errorf("invalid date_start %q: %w", start, err)
+	}
+
+	dateEnd, err := entity.ParseDate(end)
+	if err != nil {
+		return entity.CategoryEntry{}, fmt.Errorf("invalid date_end %q: %w", end, err)
+	}
+
+	dayPortion, err := entity.ParseDayPortion(portion)
+	if err != nil {
+		return entity.CategoryEntry{}, err
+	}
+
+	if label == "" {
+		label = "Event"
+	}
+
+	return entity.CategoryEntry{
+		DateStart: dateStart,
+		DateEnd:   dateEnd,
+		Label:     label,
+		Portion:   dayPortion,
+	}, nil
+}
+
+// MigrateCSVToSQLite copies every year of a CSV data tree into an empty
+// database in a single transaction and returns the number of entries copied.
+func MigrateCSVToSQLite(src *CSVStorage, dst *SQLiteStorage) (int, error) {
+	existingYears, err := dst.ListYears()
+	if err != nil {
+		return 0, err
+	}
+	if len(existingYears) > 0 {
+		return 0, errors.New("database already contains data")
+	}
+
+	years, err := src.ListYears()
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	err = dst.inTx(func(tx *sql.Tx) error {
+		for _, year := range years {
+			categories, loadErr := src.loadYearFolder(year)
+			if loadErr != nil {
+				return fmt.Errorf("failed to load year %d: %w", year, loadErr)
+			}
+
+			count, migrateErr := migrateYear(tx, year, categories)
+			if migrateErr != nil {
+				return fmt.Errorf("failed to migrate year %d: %w", year, migrateErr)
+			}
+			migrated += count
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return migrated, nil
+}
+
+func migrateYear(
+	tx *sql.Tx,
+	year int,
+	categories map[string]*entity.Category,
+) (int, error) {
+	if _, err := tx.Exec(`INSERT INTO years (year) VALUES (?)`, year); err != nil {
+		return 0, fmt.Errorf("failed to insert year: %w", err)
+	}
+
+	migrated := 0
+	for categoryName, category := range categories {
+		if _, err := ensureSQLiteCategory(tx, year, categoryName); err != nil {
+			return 0, err
+		}
+
+		for _, entry := range category.Entries {
+			if err := insertSQLiteEntry(tx, year, categoryName, entry); err != nil {
+				return 0, err
+			}
+			migrated++
+		}
+	}
+
+	return migrated, nil
+}
+
+var _ Storage = (*SQLiteStorage)(nil)
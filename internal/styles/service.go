@@ -2,7 +2,6 @@ package styles
 
 import (
 	"maps"
-	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/nsr888/lifecalendar/internal/config"
@@ -12,12 +11,16 @@ import (
 func NewService(
 	cfg *config.Config,
 	storage Storage,
-	dayStyles map[time.Time]entity.DayInfo,
+	dayIndex *entity.DayIndex,
 ) *Service {
+	if dayIndex == nil {
+		dayIndex = &entity.DayIndex{}
+	}
+
 	return &Service{
 		config:     cfg,
 		categories: GenerateCategoryStyles(cfg.Categories),
-		dayStyles:  dayStyles,
+		dayIndex:   dayIndex,
 		storage:    storage,
 	}
 }
@@ -36,59 +39,25 @@ func (s *Service) GetAllCategoryStyles() map[string]lipgloss.Style {
 	return result
 }
 
-func (s *Service) GetDayStyle(date time.Time) (entity.DayInfo, bool) {
-	dayInfo, exists := s.dayStyles[date]
-	return dayInfo, exists
+func (s *Service) GetDayStyle(date entity.Date) (entity.DayInfo, bool) {
+	return s.dayIndex.Lookup(date)
 }
 
-func (s *Service) GetAllDayStyles() map[time.Time]entity.DayInfo {
-	result := make(map[time.Time]entity.DayInfo)
-	maps.Copy(result, s.dayStyles)
-
-	return result
+func (s *Service) GetDayIndex() *entity.DayIndex {
+	return s.dayIndex
 }
 
-func ComputeYearStyles(
+// ComputePeriodStyles indexes the highest-priority category of every day in the period.
+func ComputePeriodStyles(
 	config *config.Config,
-	year int,
+	period entity.Period,
 	data *entity.CategoryName,
-) (map[time.Time]entity.DayInfo, error) {
-	startDate := time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
-	endDate := time.Date(year, 12, 31, 0, 0, 0, 0, time.Local)
-
-	result := make(map[time.Time]entity.DayInfo)
-
-	for currentDate := startDate; !currentDate.After(endDate); currentDate = currentDate.AddDate(0, 0, 1) {
-		var winningCategory string
-		winningPriority := 999
-
-		for categoryName, category := range data.Categories {
-			if _, exists := category.Dates[currentDate]; exists {
-				categoryConfig := config.GetCategoryConfig(categoryName)
-				if categoryConfig.Priority < winningPriority {
-					winningCategory = categoryName
-					winningPriority = categoryConfig.Priority
-				}
-			}
-		}
-
-		if winningCategory != "" {
-			result[currentDate] = entity.DayInfo{
-				Category: winningCategory,
-				Priority: winningPriority,
-			}
-		}
-	}
-
-	return result, nil
+) (*entity.DayIndex, error) {
+	return entity.NewDayIndex(data, period.Start, period.End, config.CategoryPriority), nil
 }
 
 func (s *Service) GetPriority(category entity.CategoryType) int {
-	categoryName := string(category)
-	if config, exists := s.config.Categories[categoryName]; exists {
-		return config.Priority
-	}
-	return 999
+	return s.config.CategoryPriority(string(category))
 }
 
 func GenerateCategoryStyles(categories map[string]config.CategoryConfig) map[string]lipgloss.Style {
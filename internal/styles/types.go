@@ -1,8 +1,6 @@
 package styles
 
 import (
-	"time"
-
 	"github.com/charmbracelet/lipgloss"
 	"github.com/nsr888/lifecalendar/internal/config"
 	"github.com/nsr888/lifecalendar/internal/entity"
@@ -15,8 +13,8 @@ type StyleService interface {
 	GetAllCategoryStyles() map[string]lipgloss.Style
 
 	// Day style access
-	GetDayStyle(date time.Time) (entity.DayInfo, bool)
-	GetAllDayStyles() map[time.Time]entity.DayInfo
+	GetDayStyle(date entity.Date) (entity.DayInfo, bool)
+	GetDayIndex() *entity.DayIndex
 
 	// Priority management for backward compatibility
 	GetPriority(category entity.CategoryType) int
@@ -26,7 +24,7 @@ type StyleService interface {
 type Service struct {
 	config     *config.Config
 	categories map[string]lipgloss.Style
-	dayStyles  map[time.Time]entity.DayInfo
+	dayIndex   *entity.DayIndex
 	storage    Storage
 }
 